@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/charmbracelet/fang"
 	"github.com/josh-allan/ahab/internal/tui"
@@ -12,12 +13,23 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	refreshInterval time.Duration
+	orphansDown     bool
+	logsOpts        ahab.LogsOptions
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "ahab",
 	Short: "Ahoy, Ahab!",
 	Long:  "Ahab is a tool to manage Docker Compose files.",
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := tui.Run(); err != nil {
+		cfg, err := ahab.LoadConfig()
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if err := tui.Run(tui.Options{RefreshInterval: refreshInterval, Config: cfg}); err != nil {
 			fmt.Println("Error:", err)
 			os.Exit(1)
 		}
@@ -28,6 +40,7 @@ func composeCommand(use, short string, fn func() error) *cobra.Command {
 	return &cobra.Command{
 		Use:   use,
 		Short: short,
+		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			if err := fn(); err != nil {
 				fmt.Println("Error:", err)
@@ -37,13 +50,46 @@ func composeCommand(use, short string, fn func() error) *cobra.Command {
 	}
 }
 
+// targetCommand is a composeCommand that accepts optional "stack" or
+// "stack:service" targets and acts on every file when none are given.
+func targetCommand(use, short string, fn func(targets ...string) error) *cobra.Command {
+	return &cobra.Command{
+		Use:   use + " [stack[:service]...]",
+		Short: short,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := fn(args...); err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
 func init() {
-	rootCmd.AddCommand(composeCommand("start", "Start all Docker Compose files", ahab.RunAllCompose))
-	rootCmd.AddCommand(composeCommand("update", "Update all Docker Compose files", ahab.UpdateAllCompose))
-	rootCmd.AddCommand(composeCommand("stop", "Stop all Docker Compose files", ahab.StopAllCompose))
-	rootCmd.AddCommand(composeCommand("down", "Stop and remove all Docker Compose resources", ahab.StopAllComposeDown))
-	rootCmd.AddCommand(composeCommand("restart", "Restart all Docker Compose files", ahab.RestartAllCompose))
+	rootCmd.Flags().DurationVar(&refreshInterval, "refresh", 0, "Re-check stack statuses in the TUI at this interval (e.g. 30s); 0 disables")
+	rootCmd.AddCommand(targetCommand("start", "Start all Docker Compose files", ahab.RunAllCompose))
+	rootCmd.AddCommand(targetCommand("update", "Update all Docker Compose files", ahab.UpdateAllCompose))
+	rootCmd.AddCommand(targetCommand("stop", "Stop all Docker Compose files", ahab.StopAllCompose))
+	rootCmd.AddCommand(targetCommand("down", "Stop and remove all Docker Compose resources", ahab.StopAllComposeDown))
+	rootCmd.AddCommand(targetCommand("restart", "Restart all Docker Compose files", ahab.RestartAllCompose))
+	rootCmd.AddCommand(targetCommand("recreate", "Recreate all containers (up -d --force-recreate)", ahab.RecreateAllCompose))
 	rootCmd.AddCommand(composeCommand("list", "List all Docker Compose files", ahab.ListIgnoreFiles))
+
+	orphansCmd := composeCommand("orphans", "List running compose projects whose files are no longer discovered", func() error {
+		return ahab.ListOrphans(orphansDown)
+	})
+	orphansCmd.Flags().BoolVar(&orphansDown, "down", false, "Run docker compose down on each orphaned project")
+	rootCmd.AddCommand(orphansCmd)
+
+	logsCmd := targetCommand("logs", "Show the merged logs of all Docker Compose files", func(targets ...string) error {
+		return ahab.Logs(logsOpts, targets...)
+	})
+	logsCmd.Flags().BoolVarP(&logsOpts.Follow, "follow", "f", false, "Follow log output")
+	logsCmd.Flags().StringVar(&logsOpts.Since, "since", "", "Show logs since a timestamp or relative time (e.g. 10m)")
+	logsCmd.Flags().StringVar(&logsOpts.Grep, "grep", "", "Only show lines matching this regular expression")
+	logsCmd.Flags().BoolVar(&logsOpts.Archive, "archive", false, "Search the logs saved by ahab capture")
+	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(targetCommand("capture", "Save the logs of the given or configured stacks to rotated files until interrupted", ahab.Capture))
 }
 
 func main() {
@@ -0,0 +1,246 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// Per-row progress of a running action.
+const (
+	progressQueued    = "queued"
+	progressRunning   = "running"
+	progressDone      = "done"
+	progressFailed    = "failed"
+	progressCancelled = "cancelled"
+)
+
+type actionProgressMsg struct {
+	action   string
+	progress ahab.FileProgress
+	next     tea.Cmd
+}
+
+// toggleMark marks or unmarks the file under the cursor, or every file
+// beneath the folder under the cursor.
+func (m *Model) toggleMark() {
+	row, ok := m.currentRow()
+	if !ok {
+		return
+	}
+	if row.kind == rowDir {
+		m.markAll(m.filesUnder(row.dir))
+		return
+	}
+	if row.kind == rowService {
+		return
+	}
+	path := m.files[row.file].path
+	if m.marked[path] {
+		delete(m.marked, path)
+	} else {
+		m.marked[path] = true
+	}
+}
+
+// markAll marks every given file, or clears the marks if they are all marked already.
+func (m *Model) markAll(indexes []int) {
+	all := true
+	for _, i := range indexes {
+		if !m.marked[m.files[i].path] {
+			all = false
+			break
+		}
+	}
+	for _, i := range indexes {
+		if all {
+			delete(m.marked, m.files[i].path)
+		} else {
+			m.marked[m.files[i].path] = true
+		}
+	}
+}
+
+// targets returns the marked files shown in the list, in list order or,
+// when none is shown, the file or service under the cursor or every file
+// beneath the folder under it. Marked files hidden by a filter are left out.
+func (m Model) targets() []ahab.Target {
+	var targets []ahab.Target
+	for _, i := range m.visible() {
+		if path := m.files[i].path; m.marked[path] {
+			targets = append(targets, ahab.Target{File: path})
+		}
+	}
+	if len(targets) > 0 {
+		return targets
+	}
+	row, ok := m.currentRow()
+	switch {
+	case !ok:
+	case row.kind == rowDir:
+		for _, i := range m.filesUnder(row.dir) {
+			targets = append(targets, ahab.Target{File: m.files[i].path})
+		}
+	case row.kind == rowService:
+		targets = append(targets, ahab.Target{File: m.files[row.file].path, Services: []string{row.service}})
+	default:
+		targets = append(targets, ahab.Target{File: m.files[row.file].path})
+	}
+	return targets
+}
+
+// hiddenMarks counts the marked files hidden by a filter.
+func (m Model) hiddenMarks() int {
+	n := len(m.marked)
+	for _, i := range m.visible() {
+		if m.marked[m.files[i].path] {
+			n--
+		}
+	}
+	return n
+}
+
+// noteHiddenMarks tells in the status bar that marked files hidden by a
+// filter were left out of an action.
+func (m *Model) noteHiddenMarks() {
+	if n := m.hiddenMarks(); n > 0 {
+		m.statusMsg += fmt.Sprintf(" (%d hidden marked, left out)", n)
+	}
+}
+
+// runTargets starts docker compose with args on every target as a batch of
+// jobs, in the same order as the CLI and within one concurrency limit shared
+// by every batch. Stacks that already have a job running are skipped, so
+// batches never overlap.
+func (m *Model) runTargets(action string, all []ahab.Target, args ...string) (tea.Model, tea.Cmd) {
+	var targets, busy []ahab.Target
+	for _, t := range all {
+		if _, running := m.jobs[t.File]; running {
+			busy = append(busy, t)
+		} else {
+			targets = append(targets, t)
+		}
+	}
+	if len(targets) == 0 {
+		if len(busy) > 0 {
+			m.statusMsg = fmt.Sprintf("%s is busy", targetName(busy[0]))
+		}
+		return *m, nil
+	}
+	if len(targets) == 1 {
+		m.statusMsg = fmt.Sprintf("%s %s...", action, targetName(targets[0]))
+	} else {
+		m.statusMsg = fmt.Sprintf("%s %d stacks...", action, len(targets))
+	}
+	if len(busy) > 0 {
+		m.statusMsg += fmt.Sprintf(" (%d busy, skipped)", len(busy))
+	}
+
+	writers := make(map[string]*lineWriter, len(targets))
+	ctxs := make(map[string]context.Context, len(targets))
+	for _, t := range targets {
+		m.progress[t.File] = progressQueued
+		writers[t.File] = newLineWriter(m.outputFor(t.File))
+		ctxs[t.File] = m.startJob(action, t, args...)
+	}
+
+	updates := make(chan ahab.FileProgress, 2*len(targets))
+	done := make(chan error, 1)
+	go func() {
+		defer close(updates)
+		setup := func(t ahab.Target) (context.Context, io.Writer) { return ctxs[t.File], writers[t.File] }
+		done <- ahab.ExecComposeTargetsTo(setup, targets, func(p ahab.FileProgress) {
+			if p.Done {
+				writers[p.File].flush()
+			}
+			updates <- p
+		}, args...)
+	}()
+	cmds := []tea.Cmd{waitForProgress(action, targets, updates, done), m.startSpinner()}
+	if m.pane == modeOutput {
+		cmds = append(cmds, m.logTickCmd())
+	}
+	return *m, tea.Batch(cmds...)
+}
+
+// waitForProgress delivers progress updates until the batch finishes.
+func waitForProgress(action string, targets []ahab.Target, updates <-chan ahab.FileProgress, done <-chan error) tea.Cmd {
+	return func() tea.Msg {
+		p, ok := <-updates
+		if !ok {
+			if err := <-done; err != nil {
+				return actionDoneMsg{action: action, msg: fmt.Sprintf("%s failed", action), err: err, targets: targets}
+			}
+			return actionDoneMsg{action: action, msg: fmt.Sprintf("%s done", action), targets: targets}
+		}
+		return actionProgressMsg{action: action, progress: p, next: waitForProgress(action, targets, updates, done)}
+	}
+}
+
+// targetName is a short label for a target: the file name and any services.
+func targetName(t ahab.Target) string {
+	name := filepath.Base(t.File)
+	if len(t.Services) > 0 {
+		name += ":" + strings.Join(t.Services, ",")
+	}
+	return name
+}
+
+// showFailure opens the output pane on the first target whose action failed,
+// unless the log viewer is open.
+func (m *Model) showFailure(targets []ahab.Target) {
+	if m.logView != nil {
+		return
+	}
+	for _, t := range targets {
+		if m.progress[t.File] == progressFailed {
+			m.stopLogStreamer()
+			m.pane = modeOutput
+			m.cursorToFile(t.File)
+			m.selectionChanged()
+			return
+		}
+	}
+}
+
+// setProgress records a progress update, reporting failures inline and
+// clearing a stack's previous error once an action on it succeeds.
+func (m *Model) setProgress(action string, p ahab.FileProgress) tea.Cmd {
+	if !p.Done {
+		m.progress[p.File] = progressRunning
+		if j, ok := m.jobs[p.File]; ok {
+			j.started = time.Now()
+		}
+		return nil
+	}
+	j := m.jobs[p.File]
+	cancelled := m.finishJob(p.File)
+	var cmd tea.Cmd
+	switch {
+	case cancelled:
+		m.progress[p.File] = progressCancelled
+	case p.Err != nil:
+		m.progress[p.File] = progressFailed
+		cmd = m.reportError(p.File, action, p.Err)
+	default:
+		m.progress[p.File] = progressDone
+		delete(m.stackErrs, p.File)
+		m.resort()
+	}
+	if j == nil {
+		return cmd
+	}
+	cmd = tea.Batch(cmd, m.recordHistory(j, m.progress[p.File], p.Err))
+	if !slices.ContainsFunc(m.files, func(f composeFile) bool { return f.path == p.File }) {
+		// The file went away while the job ran.
+		m.forgetFile(p.File)
+	}
+	return cmd
+}
@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+func TestModel_targets(t *testing.T) {
+	m := New(Options{})
+	m.root = "/"
+	m.files = []composeFile{{path: "/a.yaml"}, {path: "/b.yaml"}, {path: "/c.yaml"}}
+	m.cursor = 1
+
+	if got, want := m.targets(), []ahab.Target{{File: "/b.yaml"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("targets() without marks = %v, want %v", got, want)
+	}
+
+	m.marked["/c.yaml"] = true
+	m.marked["/a.yaml"] = true
+	if got, want := m.targets(), []ahab.Target{{File: "/a.yaml"}, {File: "/c.yaml"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("targets() with marks = %v, want %v", got, want)
+	}
+
+	// Marks hidden by a filter are left out.
+	m.filterInput.SetValue("c.yaml")
+	if got, want := m.targets(), []ahab.Target{{File: "/c.yaml"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("targets() with a filter = %v, want %v", got, want)
+	}
+	if n := m.hiddenMarks(); n != 1 {
+		t.Errorf("hiddenMarks() = %d, want 1", n)
+	}
+	m.filterInput.SetValue("")
+
+	m.markAll(m.visible())
+	if len(m.marked) != 3 {
+		t.Errorf("markAll() marked %d files, want 3", len(m.marked))
+	}
+	m.markAll(m.visible())
+	if len(m.marked) != 0 {
+		t.Errorf("markAll() on fully marked list left %d marks, want 0", len(m.marked))
+	}
+
+	m.expanded["/b.yaml"] = true
+	m.services["/b.yaml"] = map[string]string{"web": "running"}
+	m.cursor = 2 // a.yaml, b.yaml, b.yaml:web
+	if got, want := m.targets(), []ahab.Target{{File: "/b.yaml", Services: []string{"web"}}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("targets() on service row = %v, want %v", got, want)
+	}
+}
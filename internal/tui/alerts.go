@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// alertBuffer is the log buffer size of an alert watcher; watchers only
+// scan lines as they arrive, so they keep very little.
+const alertBuffer = 10
+
+type alertMsg struct{ alert ahab.Alert }
+
+// watchAlerts follows the new log lines of a file in the background and
+// sends those matching its alert rules to alerts. The stream is restarted
+// when it ends, e.g. because the stack's containers were recreated.
+func watchAlerts(file string, alerter *ahab.Alerter, alerts chan<- ahab.Alert) context.CancelFunc {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		since := time.Now()
+		for {
+			prev := since
+			ls := startLogStreamer(file, alertBuffer, logOptions{since: prev})
+			ls.onLine = func(line string) {
+				l := ahab.ParseLogLine(line)
+				if !l.Time.After(prev) {
+					return
+				}
+				since = l.Time
+				if a, ok := alerter.Check(l, time.Now()); ok {
+					select {
+					case alerts <- a:
+					default:
+					}
+				}
+			}
+			if err := ls.run(); err == nil {
+				select {
+				case <-ls.done:
+				case <-ctx.Done():
+					ls.stop()
+					return
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(eventRetryDelay):
+			}
+		}
+	}()
+	return cancel
+}
+
+// startAlerts (re)starts the alert watcher of a file, if it has alert rules.
+func (m *Model) startAlerts(f composeFile) tea.Cmd {
+	if cancel, ok := m.alertWatchers[f.path]; ok {
+		cancel()
+		delete(m.alertWatchers, f.path)
+	}
+	alerter, err := ahab.NewAlerter(m.root, f.path, m.config.Alerts, f.meta.Alerts)
+	if err != nil {
+		return m.reportError(f.path, "alert", err)
+	}
+	if alerter == nil {
+		return nil
+	}
+	m.alertWatchers[f.path] = watchAlerts(f.path, alerter, m.alerts)
+	return nil
+}
+
+func (m *Model) stopAlerts() {
+	for path, cancel := range m.alertWatchers {
+		cancel()
+		delete(m.alertWatchers, path)
+	}
+}
+
+func waitForAlert(alerts <-chan ahab.Alert) tea.Cmd {
+	return func() tea.Msg {
+		return alertMsg{<-alerts}
+	}
+}
+
+// notify sends an alert to the configured notifier.
+func (m Model) notify(a ahab.Alert) tea.Cmd {
+	cfg := m.config.Notifier
+	if cfg.Command == "" && cfg.Webhook == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := ahab.Notify(ctx, cfg, a); err != nil {
+			return notifyErrMsg{err}
+		}
+		return nil
+	}
+}
+
+type notifyErrMsg struct{ err error }
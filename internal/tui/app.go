@@ -3,13 +3,13 @@ package tui
 import (
 	"context"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	ahab "github.com/josh-allan/ahab/pkg"
@@ -20,7 +20,6 @@ type appState int
 const (
 	stateLoading appState = iota
 	stateList
-	stateActionRunning
 	stateError
 )
 
@@ -30,20 +29,44 @@ const (
 	modeInfo paneMode = iota
 	modePreview
 	modeLogs
+	modeStats
+	modeOutput
 )
 
 type composeFile struct {
-	path   string
-	status string
+	path      string
+	status    string
+	unhealthy int // containers failing their health check
+	meta      ahab.ComposeMeta
+	// metaLoaded is false until meta is read, and stays false if reading
+	// it failed.
+	metaLoaded bool
 }
 
-type filesLoadedMsg struct{ files []composeFile }
-type actionDoneMsg struct{ msg string }
+type filesLoadedMsg struct {
+	root  string
+	files []composeFile
+}
+type actionDoneMsg struct {
+	action  string
+	msg     string
+	err     error
+	targets []ahab.Target
+}
 type logTickMsg struct{}
 type errMsg struct{ err error }
 
+// Options configures the TUI.
+type Options struct {
+	// RefreshInterval re-checks every stack's status periodically when non-zero.
+	RefreshInterval time.Duration
+	// Config is the user configuration.
+	Config ahab.Config
+}
+
 type Model struct {
 	state       appState
+	root        string
 	files       []composeFile
 	cursor      int
 	pane        paneMode
@@ -55,77 +78,321 @@ type Model struct {
 	showHelp    bool
 	logStreamer *logStreamer
 	preview     string
+	events      <-chan ahab.ComposeEvent
+	eventCancel context.CancelFunc
+
+	refreshGen      int
+	refreshCancel   context.CancelFunc
+	refreshInterval time.Duration
+
+	stats   map[string]*stackStats
+	orphans []ahab.ComposeProject
+
+	marked   map[string]bool
+	progress map[string]string
+
+	filtering    bool
+	filterInput  textinput.Model
+	statusFilter string
+	palette      *commandPalette
+
+	flat      bool
+	collapsed map[string]bool
+	sortMode  string
+	order     []int // every file index in list order, set by resort
+
+	expanded map[string]bool
+	services map[string]map[string]string
+
+	output map[string]*logBuffer
+
+	stackErrs  map[string]string
+	errHistory []errorEntry
+	toast      string
+	toastID    int
+	showErrors bool
+	errCursor  int
+
+	jobs     map[string]*job
+	spinning bool
+
+	config  ahab.Config
+	keys    keyMap
+	confirm *confirmDialog
+	logView *logView
+
+	alerts        chan ahab.Alert
+	alertWatchers map[string]context.CancelFunc
+
+	previewScroll   int
+	previewResolved bool
+	resolved        map[string]string
+	resolving       string
+
+	history       []ahab.HistoryEntry
+	historyLoaded int // entries from previous sessions, at the start of history
+	showHistory   bool
+	historyCursor int
+	historyOutput bool
+
+	fileChanges <-chan struct{}
+	watchCancel context.CancelFunc
+	added       map[string]bool // stacks found by the last rediscovery
+	removed     []string        // stacks gone since the last rediscovery
+	diffGen     int
+
+	updates        map[string][]string // outdated images per stack
+	updateErrShown bool
+	lastRefresh    time.Time
+	dockerHost     string
+	summaryFocus   bool
+	summaryCursor  int
 }
 
-func New() Model {
+func New(opts Options) Model {
 	sp := spinner.New()
 	sp.Spinner = spinner.Dot
-	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("212"))
+	sp.Style = spinnerStyle
+	// Run reports a bad keymap before building the model; here unknown
+	// actions are just left out.
+	keys, _ := newKeyMap(opts.Config.Keys)
 	return Model{
-		state:   stateLoading,
-		spinner: sp,
-		pane:    modeInfo,
+		state:           stateLoading,
+		spinner:         sp,
+		pane:            modeInfo,
+		refreshInterval: opts.RefreshInterval,
+		marked:          make(map[string]bool),
+		filterInput:     newFilterInput(),
+		collapsed:       make(map[string]bool),
+		sortMode:        "path",
+		expanded:        make(map[string]bool),
+		services:        make(map[string]map[string]string),
+		output:          make(map[string]*logBuffer),
+		stackErrs:       make(map[string]string),
+		progress:        make(map[string]string),
+		jobs:            make(map[string]*job),
+		spinning:        true,
+		config:          opts.Config,
+		keys:            keys,
+		alerts:          make(chan ahab.Alert, 64),
+		alertWatchers:   make(map[string]context.CancelFunc),
+		resolved:        make(map[string]string),
+		updates:         make(map[string][]string),
 	}
 }
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(m.spinner.Tick, fetchFiles())
+	return tea.Batch(m.spinner.Tick, fetchFiles(), watchEvents(), m.refreshTickCmd(), sampleStats(), waitForAlert(m.alerts), loadHistory(), watchFiles(), fetchDockerHost(), loadUIState())
 }
 
 func fetchFiles() tea.Cmd {
 	return func() tea.Msg {
-		infos, err := ahab.FindComposeFilesForTUI()
+		root, files, err := discoverFiles()
 		if err != nil {
 			return errMsg{err}
 		}
-		var cfs []composeFile
-		for _, info := range infos {
-			cfs = append(cfs, composeFile{
-				path:   info.Path,
-				status: "unknown",
-			})
-		}
-		return filesLoadedMsg{cfs}
+		return filesLoadedMsg{root: root, files: files}
 	}
 }
 
-func refreshStatuses(files []composeFile) tea.Cmd {
-	return func() tea.Msg {
-		updated := make([]composeFile, len(files))
-		copy(updated, files)
-		for i := range updated {
-			updated[i].status = ahab.GetComposeStatus(updated[i].path)
-		}
-		return filesLoadedMsg{files: updated}
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	nm, ok := next.(Model)
+	if !ok {
+		return next, cmd
+	}
+	// Sorting by live data can move rows; unless the cursor was moved, keep
+	// it on the row it was on.
+	if nm.sortLive() && nm.state == stateList && nm.cursor == m.cursor {
+		nm.restoreCursor(m.cursorKey())
+	}
+	// Panes that load asynchronously start loading once the update settles.
+	if c := nm.fetchResolved(); c != nil {
+		return nm, tea.Batch(cmd, c)
 	}
+	return nm, cmd
 }
 
-func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 
 	case spinner.TickMsg:
-		if m.state == stateLoading {
+		if m.state == stateLoading || len(m.jobs) > 0 {
 			var cmd tea.Cmd
 			m.spinner, cmd = m.spinner.Update(msg)
 			return m, cmd
 		}
+		m.spinning = false
 
 	case filesLoadedMsg:
+		m.root = msg.root
 		m.files = msg.files
+		m.resort()
 		m.state = stateList
 		m.statusMsg = fmt.Sprintf("%d files", len(m.files))
-		return m, refreshStatuses(m.files)
+		m.clampCursor()
+		return m, tea.Batch(m.startRefresh(), fetchOrphans(), loadMeta(m.files), m.checkUpdates(m.files), m.updateTickCmd())
 
 	case actionDoneMsg:
 		m.statusMsg = msg.msg
-		m.state = stateList
-		return m, refreshStatuses(m.files)
+		if msg.err != nil {
+			m.showFailure(msg.targets)
+		}
+		cmds := []tea.Cmd{m.startRefresh(), fetchOrphans(), m.refreshExpanded()}
+		switch msg.action {
+		case "pull", "start", "recreate", "up":
+			cmds = append(cmds, m.checkUpdatesFor(msg.targets))
+		}
+		return m, tea.Batch(cmds...)
+
+	case serviceStatusMsg:
+		if msg.err == nil {
+			m.services[msg.path] = msg.statuses
+		}
+
+	case fileMetaMsg:
+		m.setFileMeta(msg)
+		for _, f := range m.files {
+			if f.path == msg.path {
+				return m, m.startAlerts(f)
+			}
+		}
+
+	case alertMsg:
+		return m, tea.Batch(m.reportAlert(msg.alert), m.notify(msg.alert), waitForAlert(m.alerts))
+
+	case resolvedMsg:
+		m.setResolved(msg)
+
+	case editorDoneMsg:
+		return m, m.edited(msg)
+
+	case validatedMsg:
+		m.validated(msg)
+
+	case shellProbeMsg:
+		return m, m.execShell(msg)
+
+	case shellDoneMsg:
+		return m, m.shellDone(msg)
+
+	case notifyErrMsg:
+		return m, m.reportError("", "notify", msg.err)
+
+	case historyLoadedMsg:
+		return m, m.setHistory(msg)
+
+	case historyErrMsg:
+		return m, m.reportError("", "history", msg.err)
+
+	case uiStateLoadedMsg:
+		return m, m.setUIState(msg)
+
+	case uiStateErrMsg:
+		return m, m.reportError("", "state", msg.err)
+
+	case actionProgressMsg:
+		return m, tea.Batch(m.setProgress(msg.action, msg.progress), msg.next)
+
+	case toastExpireMsg:
+		if msg.id == m.toastID {
+			m.toast = ""
+		}
+
+	case orphansMsg:
+		if msg.err == nil {
+			m.orphans = msg.orphans
+		}
+
+	case fileStatusMsg:
+		m.setFileStatus(msg)
+
+	case refreshStatusMsg:
+		if msg.gen != m.refreshGen {
+			return m, nil
+		}
+		m.setFileStatus(msg.status)
+		return m, waitForStatus(msg.gen, msg.results)
+
+	case refreshDoneMsg:
+		if msg.gen == m.refreshGen {
+			m.cancelRefresh()
+			m.lastRefresh = time.Now()
+		}
+
+	case updatesMsg:
+		m.setUpdates(msg)
+
+	case updateTickMsg:
+		return m, tea.Batch(m.checkUpdates(m.files), m.updateTickCmd())
+
+	case dockerHostMsg:
+		m.dockerHost = msg.host
+
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
+
+	case refreshTickMsg:
+		if m.state == stateList {
+			return m, tea.Batch(m.startRefresh(), m.refreshTickCmd())
+		}
+		return m, m.refreshTickCmd()
+
+	case eventsStartedMsg:
+		m.events = msg.events
+		m.eventCancel = msg.cancel
+		return m, waitForEvent(m.events)
+
+	case composeEventMsg:
+		return m, tea.Batch(m.handleComposeEvent(msg.event), waitForEvent(m.events))
+
+	case eventsClosedMsg:
+		m.stopEvents()
+		m.events = nil
+		return m, tea.Tick(eventRetryDelay, func(time.Time) tea.Msg { return eventsRetryMsg{} })
+
+	case eventsRetryMsg:
+		return m, watchEvents()
+
+	case watchStartedMsg:
+		m.fileChanges = msg.changes
+		m.watchCancel = msg.cancel
+		return m, waitForChange(m.fileChanges)
+
+	case filesChangedMsg:
+		return m, tea.Batch(rediscoverFiles(), waitForChange(m.fileChanges))
+
+	case filesRediscoveredMsg:
+		return m, m.rediscovered(msg)
+
+	case diffExpireMsg:
+		if msg.gen == m.diffGen {
+			m.added = nil
+			m.removed = nil
+		}
+
+	case watchClosedMsg:
+		m.stopWatch()
+		m.fileChanges = nil
+		return m, tea.Tick(eventRetryDelay, func(time.Time) tea.Msg { return watchRetryMsg{} })
+
+	case watchRetryMsg:
+		return m, watchFiles()
+
+	case statsTickMsg:
+		return m, sampleStats()
+
+	case statsMsg:
+		if msg.err == nil {
+			m.setStats(msg.stats)
+		}
+		return m, statsTickCmd()
 
 	case logTickMsg:
-		if m.pane == modeLogs {
+		if m.pane == modeLogs || m.logView != nil || m.pane == modeOutput && len(m.jobs) > 0 {
 			return m, m.logTickCmd()
 		}
 
@@ -137,13 +404,36 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch m.state {
 		case stateLoading:
 			if msg.String() == "q" || msg.String() == "ctrl+c" {
+				m.shutdown()
 				return m, tea.Quit
 			}
-		case stateList, stateActionRunning:
+		case stateList:
+			if m.filtering {
+				return m.updateFilter(msg)
+			}
+			if m.palette != nil {
+				return m.updatePalette(msg)
+			}
+			if m.confirm != nil {
+				return m.updateConfirm(msg)
+			}
+			if m.logView != nil {
+				return m.updateLogView(msg)
+			}
+			if m.showErrors {
+				return m.updateErrors(msg)
+			}
+			if m.showHistory {
+				return m.updateHistory(msg)
+			}
+			if m.summaryFocus {
+				return m.updateSummary(msg)
+			}
 			return m.updateList(msg)
 		case stateError:
 			switch msg.String() {
 			case "q", "ctrl+c":
+				m.shutdown()
 				return m, tea.Quit
 			case "r":
 				m.state = stateLoading
@@ -157,54 +447,77 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m Model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.showHelp {
-		switch msg.String() {
-		case "?", "esc", "q":
+		if action := m.keys.action(msg.String()); action == "help" || action == "quit" || action == "clear" {
 			m.showHelp = false
 		}
 		return m, nil
 	}
 
-	switch msg.String() {
-	case "ctrl+c", "q":
-		m.stopLogStreamer()
+	switch m.keys.action(msg.String()) {
+	case "quit":
+		m.shutdown()
 		return m, tea.Quit
-	case "up", "k":
-		if m.cursor > 0 {
-			m.cursor--
-			m.preview = ""
-			if m.pane == modeLogs {
-				m.restartLogStreamer()
-			}
-		}
-	case "down", "j":
-		if m.cursor < len(m.files)-1 {
-			m.cursor++
-			m.preview = ""
-			if m.pane == modeLogs {
-				m.restartLogStreamer()
-			}
-		}
-	case "tab", "2":
+	case "cursor-up":
+		m.moveCursor(-1)
+	case "cursor-down":
+		m.moveCursor(1)
+	case "pane-preview":
 		m.pane = modePreview
 		m.loadPreview()
-	case "3":
+	case "pane-logs":
 		m.pane = modeLogs
 		m.restartLogStreamer()
 		return m, m.logTickCmd()
-	case "1":
+	case "pane-info":
 		m.stopLogStreamer()
 		m.pane = modeInfo
-	case "s":
-		return m.runAction("start", "up", "-d")
-	case "x":
-		return m.runAction("stop", "stop")
-	case "d":
-		return m.runAction("down", "down")
-	case "r":
-		return m.runAction("restart", "restart")
-	case "p":
-		return m.runAction("pull", "pull")
-	case "l":
+	case "pane-stats":
+		m.stopLogStreamer()
+		m.pane = modeStats
+	case "pane-output":
+		m.stopLogStreamer()
+		m.pane = modeOutput
+		return m, m.logTickCmd()
+	case "start":
+		return m.requestAction("start", "up", "-d")
+	case "stop":
+		return m.requestAction("stop", "stop")
+	case "down":
+		return m.requestAction("down", "down")
+	case "down-options":
+		if targets := m.targets(); len(targets) > 0 {
+			m.openConfirm("down", targets, []string{"down"})
+		}
+	case "restart":
+		return m.requestAction("restart", "restart")
+	case "pull":
+		return m.requestAction("pull", "pull")
+	case "recreate":
+		return m.requestAction("recreate", "up", "-d", "--force-recreate")
+	case "log-viewer":
+		return m, m.openLogView()
+	case "resolved":
+		m.toggleResolved()
+	case "edit":
+		if f, ok := m.selected(); ok {
+			return m, m.editFile(f.path, 0)
+		}
+	case "shell":
+		return m, m.openShell()
+	case "palette":
+		return m, m.openPalette()
+	case "scroll-down", "scroll-up", "half-page-down", "half-page-up", "page-down", "page-up":
+		if m.pane == modePreview {
+			step := map[string]int{
+				"scroll-down": 1, "scroll-up": -1,
+				"half-page-down": m.height / 2, "half-page-up": -m.height / 2,
+				"page-down": m.height, "page-up": -m.height,
+			}
+			m.scrollPreview(step[m.keys.action(msg.String())])
+		}
+	case "cancel":
+		m.cancelJobs()
+	case "logs":
 		if m.pane == modeLogs {
 			m.stopLogStreamer()
 			m.pane = modeInfo
@@ -213,46 +526,134 @@ func (m Model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.restartLogStreamer()
 			return m, m.logTickCmd()
 		}
-	case "?":
+	case "mark":
+		m.toggleMark()
+		m.moveCursor(1)
+	case "mark-all":
+		all := make([]int, len(m.files))
+		for i := range m.files {
+			all[i] = i
+		}
+		m.markAll(all)
+	case "mark-visible":
+		m.markAll(m.visible())
+	case "toggle":
+		row, ok := m.currentRow()
+		switch {
+		case !ok:
+		case row.kind == rowDir:
+			m.toggleCollapse(!m.collapsed[row.dir])
+		default:
+			return m, m.toggleExpand(!m.expanded[m.files[row.file].path])
+		}
+	case "collapse":
+		if row, ok := m.currentRow(); ok && row.kind != rowDir && m.expanded[m.files[row.file].path] {
+			m.toggleExpand(false)
+		} else {
+			m.toggleCollapse(true)
+		}
+		m.selectionChanged()
+	case "expand":
+		if row, ok := m.currentRow(); ok && row.kind == rowFile {
+			return m, m.toggleExpand(true)
+		}
+		m.toggleCollapse(false)
+	case "tree":
+		m.flat = !m.flat
+		m.resort()
+		m.clampCursor()
+		m.selectionChanged()
+	case "filter":
+		m.filtering = true
+		return m, m.filterInput.Focus()
+	case "status-filter":
+		m.cycleStatusFilter()
+		m.selectionChanged()
+	case "sort":
+		return m, m.cycleSort()
+	case "errors":
+		m.showErrors = true
+		m.errCursor = 0
+	case "history":
+		m.showHistory = true
+		m.historyCursor = 0
+	case "summary":
+		m.summaryFocus = true
+	case "clear":
+		if m.toast != "" {
+			m.toast = ""
+		} else if len(m.marked) > 0 {
+			m.marked = make(map[string]bool)
+		} else {
+			m.filterInput.SetValue("")
+			m.statusFilter = ""
+			m.clampCursor()
+			m.selectionChanged()
+		}
+	case "help":
 		m.showHelp = !m.showHelp
 	}
 	return m, nil
 }
 
-func (m *Model) runAction(action string, args ...string) (tea.Model, tea.Cmd) {
-	if len(m.files) == 0 {
-		return *m, nil
-	}
-	m.state = stateActionRunning
-	m.statusMsg = fmt.Sprintf("%s %s...", action, filepath.Base(m.files[m.cursor].path))
-	file := m.files[m.cursor].path
-	return *m, func() tea.Msg {
-		ctx := context.Background()
-		if err := ahab.ExecCompose(ctx, io.Discard, io.Discard, file, args...); err != nil {
-			return errMsg{err}
-		}
-		return actionDoneMsg{fmt.Sprintf("%s done", action)}
+// selected returns the file under the cursor, if the cursor is on a file or
+// one of its services.
+func (m Model) selected() (composeFile, bool) {
+	row, ok := m.currentRow()
+	if !ok || row.kind == rowDir {
+		return composeFile{}, false
 	}
+	return m.files[row.file], true
 }
 
-func (m *Model) loadPreview() {
-	if m.preview != "" || len(m.files) == 0 {
+// moveCursor moves the selection by delta rows, reloading the selection's panes.
+func (m *Model) moveCursor(delta int) {
+	next := m.cursor + delta
+	if next < 0 || next >= len(m.rows()) {
 		return
 	}
-	data, err := os.ReadFile(m.files[m.cursor].path)
-	if err != nil {
-		m.preview = fmt.Sprintf("error reading file: %v", err)
-		return
+	m.cursor = next
+	m.selectionChanged()
+}
+
+// clampCursor keeps the cursor inside the filtered list.
+func (m *Model) clampCursor() {
+	if n := len(m.rows()); m.cursor >= n {
+		m.cursor = max(n-1, 0)
+	}
+}
+
+// selectionChanged reloads the panes that depend on the selected file.
+func (m *Model) selectionChanged() {
+	m.preview = ""
+	m.previewScroll = 0
+	if m.pane == modePreview {
+		m.loadPreview()
+	}
+	if m.pane == modeLogs {
+		f, _ := m.selected()
+		if m.logStreamer == nil || m.logStreamer.file != f.path {
+			m.restartLogStreamer()
+		}
 	}
-	m.preview = string(data)
 }
 
 func (m *Model) restartLogStreamer() {
+	m.streamLogs(logOptions{})
+}
+
+// streamLogs restarts the log stream of the selected file with opts.
+func (m *Model) streamLogs(opts logOptions) {
 	m.stopLogStreamer()
-	if len(m.files) == 0 {
+	f, ok := m.selected()
+	if !ok {
 		return
 	}
-	ls := startLogStreamer(m.files[m.cursor].path)
+	size := m.config.LogBuffer
+	if size < 1 {
+		size = ahab.DefaultConfig().LogBuffer
+	}
+	ls := startLogStreamer(f.path, size, opts)
 	m.logStreamer = ls
 	if err := ls.run(); err != nil {
 		m.logStreamer = nil
@@ -267,6 +668,16 @@ func (m *Model) stopLogStreamer() {
 	}
 }
 
+// shutdown stops every background process before quitting.
+func (m *Model) shutdown() {
+	m.stopLogStreamer()
+	m.stopAlerts()
+	m.stopEvents()
+	m.stopWatch()
+	m.cancelRefresh()
+	m.cancelAllJobs()
+}
+
 func (m Model) logTickCmd() tea.Cmd {
 	return tea.Tick(250*time.Millisecond, func(t time.Time) tea.Msg {
 		return logTickMsg{}
@@ -287,29 +698,62 @@ func (m Model) View() string {
 
 	leftWidth := m.width / 2
 	rightWidth := m.width - leftWidth
-	contentHeight := m.height - 2
-
+	contentHeight := m.contentHeight()
 	left := lipgloss.NewStyle().Width(leftWidth).Height(contentHeight).Render(m.renderList(contentHeight))
 	right := lipgloss.NewStyle().Width(rightWidth).Height(contentHeight).Render(m.renderRightPane(contentHeight))
 	body := lipgloss.JoinHorizontal(lipgloss.Top, left, right)
 
 	statusBar := statusStyle.Render(fmt.Sprintf("  %s  |  %s", m.statusMsg, "? help  q quit"))
-	view := body + "\n" + statusBar
+	if m.filtering {
+		statusBar = statusStyle.Render(m.filterInput.View())
+	}
+	if m.palette != nil {
+		statusBar = m.renderPalette()
+	}
+	view := m.renderSummary() + "\n" + body + "\n" + statusBar
+	if m.toast != "" {
+		view = m.renderSummary() + "\n" + body + "\n" + m.renderToast() + "\n" + statusBar
+	}
 
 	if m.showHelp {
 		view = m.renderHelpOverlay(view)
 	}
+	if m.showErrors {
+		view = m.renderErrorsOverlay()
+	}
+	if m.showHistory {
+		view = m.renderHistoryOverlay()
+	}
+	if m.logView != nil {
+		view = m.renderLogView()
+	}
+	if m.confirm != nil {
+		view = m.renderConfirmOverlay()
+	}
 	return view
 }
 
 func (m Model) renderList(height int) string {
 	var b strings.Builder
-	b.WriteString(titleStyle.Render("ahab") + "\n\n")
+	title := titleStyle.Render("ahab")
+	if m.statusFilter != "" {
+		title += dimStyle.Render("status: " + m.statusFilter)
+	}
+	if q := m.filterInput.Value(); q != "" && !m.filtering {
+		title += dimStyle.Render("/" + q)
+	}
+	if m.sortMode != "path" {
+		title += dimStyle.Render("sort: " + m.sortMode)
+	}
+	b.WriteString(title + "\n\n")
 
+	rows := m.rows()
 	if len(m.files) == 0 {
 		b.WriteString(dimStyle.Render("  no compose files found") + "\n")
+	} else if len(rows) == 0 {
+		b.WriteString(dimStyle.Render("  no files match the filter") + "\n")
 	} else {
-		maxRows := height - 4
+		maxRows := height - 4 - m.orphanRows() - m.removedRows()
 		if maxRows < 1 {
 			maxRows = 1
 		}
@@ -318,31 +762,95 @@ func (m Model) renderList(height int) string {
 			start = m.cursor - maxRows + 1
 		}
 		end := start + maxRows
-		if end > len(m.files) {
-			end = len(m.files)
+		if end > len(rows) {
+			end = len(rows)
 		}
 
-		for i := start; i < end; i++ {
-			f := m.files[i]
-			indicator := statusIndicator(f.status)
-			name := filepath.Base(f.path)
-			line := fmt.Sprintf("%s %s", indicator, name)
-			if i == m.cursor {
+		for row := start; row < end; row++ {
+			var line string
+			switch rows[row].kind {
+			case rowDir:
+				line = m.renderDirRow(rows[row])
+			case rowService:
+				line = m.renderServiceRow(rows[row])
+			default:
+				line = m.renderFileRow(rows[row])
+			}
+			if row == m.cursor {
 				b.WriteString(selectedStyle.Render(line) + "\n")
 			} else {
 				b.WriteString(normalStyle.Render(line) + "\n")
 			}
 		}
 	}
+	b.WriteString(m.renderRemoved())
+	b.WriteString(m.renderOrphans())
 	return b.String()
 }
 
+func (m Model) renderFileRow(row listRow) string {
+	f := m.files[row.file]
+	indicator := statusIndicator(f.status)
+	name := filepath.Base(f.path)
+	match, _ := m.matchFile(f)
+	if match.field == "name" {
+		name = highlight(name, match.positions)
+	} else if match.field != "" {
+		name += dimStyle.Render(match.field + ": " + highlight(match.text, match.positions))
+	}
+	if m.flat {
+		if dir := m.relDir(f.path); dir != "" {
+			name = dir + "/" + name
+		}
+	}
+	if m.added[f.path] {
+		name += " " + successStyle.Render("new")
+	}
+	mark := " "
+	if m.marked[f.path] {
+		mark = "*"
+	}
+	line := fmt.Sprintf("%s%s%s %s", mark, strings.Repeat("  ", row.depth), indicator, name)
+	if j, ok := m.jobs[f.path]; ok {
+		line += " " + m.renderJob(j)
+	} else if p := m.progress[f.path]; p != "" {
+		line += " " + progressIndicator(p)
+	}
+	if _, ok := m.stackErrs[f.path]; ok {
+		line += " " + errorMarkStyle.Render("!")
+	}
+	if f.unhealthy > 0 {
+		line += " " + failureStyle.Render("✚")
+	}
+	if len(m.updates[f.path]) > 0 {
+		line += " " + infoStyle.Render("⬆")
+	}
+	if st := m.stats[f.path]; st != nil {
+		line += dimStyle.Render(fmt.Sprintf("%5.1f%% %s", st.cpu, ahab.FormatBytes(st.mem)))
+	}
+	return line
+}
+
+// contentHeight is the height of the list and the right pane: the screen
+// less the summary bar, the status bar and a toast.
+func (m Model) contentHeight() int {
+	height := m.height - 3
+	if m.toast != "" {
+		height--
+	}
+	return height
+}
+
 func (m Model) renderRightPane(height int) string {
 	switch m.pane {
 	case modePreview:
 		return m.renderPreview(height)
 	case modeLogs:
 		return m.renderLogs(height)
+	case modeStats:
+		return m.renderStats(height)
+	case modeOutput:
+		return m.renderOutput(height)
 	default:
 		return m.renderInfo(height)
 	}
@@ -351,32 +859,37 @@ func (m Model) renderRightPane(height int) string {
 func (m Model) renderInfo(height int) string {
 	var b strings.Builder
 	b.WriteString(titleStyle.Render("info") + "\n\n")
-	if len(m.files) == 0 {
-		b.WriteString(dimStyle.Render("  no file selected") + "\n")
+	if row, ok := m.currentRow(); ok && row.kind == rowDir {
+		idx := m.filesUnder(row.dir)
+		b.WriteString(normalStyle.Render(fmt.Sprintf("Folder: %s", filepath.Join(m.root, row.dir))) + "\n")
+		b.WriteString(normalStyle.Render(fmt.Sprintf("Stacks: %d", len(idx))) + "\n")
+		b.WriteString(normalStyle.Render(fmt.Sprintf("Status: %s", m.aggregateStatus(idx))) + "\n\n")
+		b.WriteString(helpStyle.Render("actions apply to every stack in this folder"))
 		return b.String()
 	}
-	f := m.files[m.cursor]
-	b.WriteString(normalStyle.Render(fmt.Sprintf("Path:   %s", f.path)) + "\n")
-	b.WriteString(normalStyle.Render(fmt.Sprintf("Status: %s", f.status)) + "\n\n")
-	b.WriteString(helpStyle.Render("s start  x stop  d down  r restart  p pull  l logs"))
-	return b.String()
-}
-
-func (m Model) renderPreview(height int) string {
-	var b strings.Builder
-	b.WriteString(titleStyle.Render("preview") + "\n\n")
-	if m.preview == "" {
-		b.WriteString(dimStyle.Render("  loading...") + "\n")
+	f, ok := m.selected()
+	if !ok {
+		b.WriteString(dimStyle.Render("  no file selected") + "\n")
 		return b.String()
 	}
-	lines := strings.Split(m.preview, "\n")
-	maxLines := height - 3
-	if len(lines) > maxLines {
-		lines = lines[:maxLines]
+	b.WriteString(normalStyle.Render(fmt.Sprintf("Path:    %s", f.path)) + "\n")
+	if row, _ := m.currentRow(); row.kind == rowService {
+		b.WriteString(normalStyle.Render(fmt.Sprintf("Service: %s", row.service)) + "\n")
+		b.WriteString(normalStyle.Render(fmt.Sprintf("Status:  %s", m.serviceStatus(f.path, row.service))) + "\n\n")
+	} else {
+		status := f.status
+		if f.unhealthy > 0 {
+			status += fmt.Sprintf(" (%d unhealthy)", f.unhealthy)
+		}
+		b.WriteString(normalStyle.Render(fmt.Sprintf("Status:  %s", status)) + "\n\n")
 	}
-	for _, line := range lines {
-		b.WriteString(normalStyle.Render(line) + "\n")
+	if images := m.updates[f.path]; len(images) > 0 {
+		b.WriteString(normalStyle.Render("Updates: "+strings.Join(images, ", ")) + "\n\n")
 	}
+	if e, ok := m.stackErrs[f.path]; ok {
+		b.WriteString(errorStyle.Render("Error:   "+e) + "\n\n")
+	}
+	b.WriteString(helpStyle.Render(m.keys.hints("start", "stop", "down", "restart", "pull", "recreate", "logs")))
 	return b.String()
 }
 
@@ -394,51 +907,66 @@ func (m Model) renderLogs(height int) string {
 		start = len(lines) - maxLines
 	}
 	for _, line := range lines[start:] {
-		b.WriteString(logStyle.Render(line) + "\n")
+		b.WriteString(logStyle.Render(formatLogLine(ahab.ParseLogLine(line), false, nil)) + "\n")
 	}
 	return b.String()
 }
 
 func (m Model) renderHelpOverlay(background string) string {
-	help := `
-  ahab - keyboard shortcuts
-
-  j/k or ↑/↓   navigate files
-  tab/1/2/3    switch pane
-  s            start
-  x            stop
-  d            down
-  r            restart
-  p            pull
-  l            toggle logs
-  ?            toggle help
-  q/ctrl+c     quit
-
-  press ? or esc to close
-`
-	overlay := lipgloss.NewStyle().
-		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("212")).
-		Padding(1, 2).
-		Render(help)
+	lines := m.keys.helpLines()
+	half := (len(lines) + 1) / 2
+	columns := lipgloss.JoinHorizontal(lipgloss.Top,
+		strings.Join(lines[:half], "\n"), "    ", strings.Join(lines[half:], "\n"))
+	help := titleStyle.Render("ahab - keyboard shortcuts") + "\n\n" + columns + "\n\n" +
+		helpStyle.Render("press "+strings.Join(append(m.keys.keys("help"), m.keys.keys("clear")...), "/")+" to close")
+	overlay := helpOverlayStyle.Render(help)
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, overlay)
 }
 
+func progressIndicator(progress string) string {
+	switch progress {
+	case progressRunning:
+		return warningStyle.Render("⟳ " + progress)
+	case progressDone:
+		return successStyle.Render("✓ " + progress)
+	case progressFailed:
+		return failureStyle.Render("✗ " + progress)
+	case progressCancelled:
+		return warningStyle.Render("⊘ " + progress)
+	default:
+		return unknownStyle.Render("… " + progress)
+	}
+}
+
 func statusIndicator(status string) string {
 	switch status {
 	case "running":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("76")).Render("●")
+		return successStyle.Render("●")
 	case "stopped":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("○")
+		return failureStyle.Render("○")
 	case "partial":
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render("◐")
+		return warningStyle.Render("◐")
+	case "orphan":
+		return warningStyle.Render("◌")
 	default:
-		return lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("?")
+		return unknownStyle.Render("?")
 	}
 }
 
-func Run() error {
-	p := tea.NewProgram(New(), tea.WithAltScreen())
-	_, err := p.Run()
+func Run(opts Options) error {
+	if _, err := newKeyMap(opts.Config.Keys); err != nil {
+		return err
+	}
+	theme, err := loadTheme(opts.Config.Theme, opts.Config.Colors, os.Getenv("NO_COLOR") != "")
+	if err != nil {
+		return err
+	}
+	applyTheme(theme)
+	progOpts := []tea.ProgramOption{tea.WithAltScreen()}
+	if opts.Config.Mouse {
+		progOpts = append(progOpts, tea.WithMouseCellMotion())
+	}
+	p := tea.NewProgram(New(opts), progOpts...)
+	_, err = p.Run()
 	return err
 }
@@ -0,0 +1,186 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// confirmOption is an extra flag the user can tick in a confirmation modal.
+type confirmOption struct {
+	label   string
+	args    []string
+	checked bool
+}
+
+// actionOptions are the extra flags offered per action.
+var actionOptions = map[string][]confirmOption{
+	"down": {
+		{label: "remove named volumes", args: []string{"--volumes"}},
+		{label: "remove orphan containers", args: []string{"--remove-orphans"}},
+		{label: "remove locally built images", args: []string{"--rmi", "local"}},
+	},
+	"start": {
+		{label: "remove orphan containers", args: []string{"--remove-orphans"}},
+	},
+	"recreate": {
+		{label: "remove orphan containers", args: []string{"--remove-orphans"}},
+	},
+}
+
+// confirmDialog asks before running an action on some targets, or, when
+// editLine is set, before reopening the target in the editor at that line.
+type confirmDialog struct {
+	action   string
+	args     []string
+	targets  []ahab.Target
+	options  []confirmOption
+	cursor   int
+	title    string // replaces the default question
+	message  string // shown below the title, e.g. a validation error
+	editLine int
+}
+
+// requestAction runs an action right away, or first asks for confirmation
+// when the config requires it for the action and its targets.
+func (m *Model) requestAction(action string, args ...string) (tea.Model, tea.Cmd) {
+	_, cmd := m.requestTargets(action, m.targets(), args...)
+	m.noteHiddenMarks()
+	return *m, cmd
+}
+
+// requestTargets is requestAction on the given targets.
+func (m *Model) requestTargets(action string, targets []ahab.Target, args ...string) (tea.Model, tea.Cmd) {
+	if len(targets) == 0 {
+		return *m, nil
+	}
+	if m.needsConfirm(action, targets) {
+		m.openConfirm(action, targets, args)
+		return *m, nil
+	}
+	return m.runTargets(action, targets, args...)
+}
+
+// openConfirm opens the confirmation modal, with the action's extra options.
+func (m *Model) openConfirm(action string, targets []ahab.Target, args []string) {
+	opts := make([]confirmOption, len(actionOptions[action]))
+	copy(opts, actionOptions[action])
+	m.confirm = &confirmDialog{action: action, args: args, targets: targets, options: opts}
+}
+
+// needsConfirm reports whether a confirm rule matches the action. Rules are
+// "action", or "action:tag" to match only stacks tagged in x-ahab. A stack
+// whose tags aren't known, because they are still loading or failed to
+// load, matches every tag.
+func (m Model) needsConfirm(action string, targets []ahab.Target) bool {
+	for _, rule := range m.config.Confirm {
+		ruleAction, tag, tagged := strings.Cut(rule, ":")
+		if ruleAction != action {
+			continue
+		}
+		if !tagged {
+			return true
+		}
+		for _, t := range targets {
+			for _, f := range m.files {
+				if f.path == t.File && (!f.metaLoaded || f.meta.HasTag(tag)) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// updateConfirm handles keys while the confirmation modal is open.
+func (m Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := m.confirm
+	if msg.String() == "ctrl+c" {
+		m.shutdown()
+		return m, tea.Quit
+	}
+	switch m.keys.in("confirm", msg.String()) {
+	case "no":
+		m.confirm = nil
+		if c.action != "" {
+			m.statusMsg = c.action + " cancelled"
+		}
+	case "up":
+		if c.cursor > 0 {
+			c.cursor--
+		}
+	case "down":
+		if c.cursor < len(c.options)-1 {
+			c.cursor++
+		}
+	case "toggle":
+		if len(c.options) > 0 {
+			c.options[c.cursor].checked = !c.options[c.cursor].checked
+		}
+	case "yes":
+		m.confirm = nil
+		if c.editLine > 0 {
+			return m, m.editFile(c.targets[0].File, c.editLine)
+		}
+		args := append([]string{}, c.args...)
+		for _, o := range c.options {
+			if o.checked {
+				args = append(args, o.args...)
+			}
+		}
+		return m.runTargets(c.action, c.targets, args...)
+	}
+	return m, nil
+}
+
+func (m Model) renderConfirmOverlay() string {
+	c := m.confirm
+	var b strings.Builder
+	title := c.title
+	if title == "" {
+		title = fmt.Sprintf("%s %d stack(s)?", c.action, len(c.targets))
+	}
+	b.WriteString(titleStyle.Render(title) + "\n\n")
+	if c.message != "" {
+		width := max(min(m.width-12, 100), 20)
+		b.WriteString(errorStyle.Width(width).Render(c.message) + "\n\n")
+	}
+	for i, t := range c.targets {
+		if i == 8 {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("... and %d more", len(c.targets)-i)) + "\n")
+			break
+		}
+		b.WriteString(normalStyle.Render(targetName(t)) + "\n")
+	}
+	if len(c.args) > 0 {
+		b.WriteString("\n" + dimStyle.Render("docker compose "+strings.Join(c.args, " ")) + "\n")
+	}
+	if n := m.hiddenMarks(); n > 0 && c.editLine == 0 {
+		b.WriteString(warningStyle.Render(fmt.Sprintf(" %d marked stack(s) hidden by the filter are left out", n)) + "\n")
+	}
+	if len(c.options) > 0 {
+		b.WriteString("\n")
+		for i, o := range c.options {
+			box := "[ ]"
+			if o.checked {
+				box = "[x]"
+			}
+			line := fmt.Sprintf("%s %s (%s)", box, o.label, strings.Join(o.args, " "))
+			if i == c.cursor {
+				b.WriteString(selectedStyle.Render(line) + "\n")
+			} else {
+				b.WriteString(normalStyle.Render(line) + "\n")
+			}
+		}
+	}
+	help := m.keys.overlayHints("confirm", "yes", "no")
+	if len(c.options) > 0 {
+		help = m.keys.overlayHints("confirm", "toggle", "down", "up", "yes", "no")
+	}
+	b.WriteString("\n" + helpStyle.Render(help))
+	overlay := confirmOverlayStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, overlay)
+}
@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"testing"
+
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+func TestModel_needsConfirm(t *testing.T) {
+	m := New(Options{Config: ahab.Config{Confirm: []string{"down", "stop:critical"}}})
+	m.files = []composeFile{
+		{path: "/db.yaml", meta: ahab.ComposeMeta{Tags: []string{"critical"}}, metaLoaded: true},
+		{path: "/web.yaml", metaLoaded: true},
+		{path: "/new.yaml"},
+	}
+
+	tests := []struct {
+		action  string
+		targets []ahab.Target
+		want    bool
+	}{
+		{"down", []ahab.Target{{File: "/web.yaml"}}, true},
+		{"stop", []ahab.Target{{File: "/web.yaml"}}, false},
+		{"stop", []ahab.Target{{File: "/web.yaml"}, {File: "/db.yaml"}}, true},
+		{"restart", []ahab.Target{{File: "/db.yaml"}}, false},
+		// Tags that haven't loaded can't rule the stack out.
+		{"stop", []ahab.Target{{File: "/new.yaml"}}, true},
+		{"restart", []ahab.Target{{File: "/new.yaml"}}, false},
+	}
+	for _, tt := range tests {
+		if got := m.needsConfirm(tt.action, tt.targets); got != tt.want {
+			t.Errorf("needsConfirm(%q, %v) = %v, want %v", tt.action, tt.targets, got, tt.want)
+		}
+	}
+}
+
+func TestModel_openConfirm(t *testing.T) {
+	m := New(Options{})
+	m.openConfirm("down", []ahab.Target{{File: "/web.yaml"}}, []string{"down"})
+	m.confirm.cursor = 2
+	m.confirm.options[2].checked = true
+
+	var got []string
+	for _, o := range m.confirm.options {
+		if o.checked {
+			got = append(got, o.args...)
+		}
+	}
+	if len(got) != 2 || got[0] != "--rmi" || got[1] != "local" {
+		t.Errorf("checked args = %v, want [--rmi local]", got)
+	}
+	if actionOptions["down"][2].checked {
+		t.Error("openConfirm shared options with actionOptions")
+	}
+}
@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+type editorDoneMsg struct {
+	path string
+	err  error
+}
+
+type validatedMsg struct {
+	path string
+	err  error
+}
+
+// errorLine finds the line number in a docker compose config error.
+var errorLine = regexp.MustCompile(`line (\d+)`)
+
+// editor returns the user's editor command: $VISUAL, $EDITOR or vi.
+func editor() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
+
+// editorArgs returns the command that opens file in an editor, at line if
+// it is positive.
+func editorArgs(editor []string, file string, line int) []string {
+	args := append([]string{}, editor...)
+	if line <= 0 {
+		return append(args, file)
+	}
+	switch filepath.Base(editor[0]) {
+	case "code", "codium", "code-insiders":
+		return append(args, "-g", fmt.Sprintf("%s:%d", file, line))
+	case "subl", "zed":
+		return append(args, fmt.Sprintf("%s:%d", file, line))
+	default:
+		// vi, vim, nvim, nano, emacs, micro, kak and most others.
+		return append(args, fmt.Sprintf("+%d", line), file)
+	}
+}
+
+// editFile suspends the TUI and opens a file in the user's editor.
+func (m *Model) editFile(path string, line int) tea.Cmd {
+	args := editorArgs(editor(), path, line)
+	cmd := exec.Command(args[0], args[1:]...)
+	m.statusMsg = "editing " + filepath.Base(path)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorDoneMsg{path: path, err: err}
+	})
+}
+
+// validate checks an edited file with docker compose config.
+func validate(path string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_, err := ahab.ComposeConfig(ctx, path)
+		return validatedMsg{path: path, err: err}
+	}
+}
+
+// edited refreshes what is derived from a file after it was edited and
+// starts validating it.
+func (m *Model) edited(msg editorDoneMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.reportError(msg.path, "edit", msg.err)
+	}
+	m.preview = ""
+	delete(m.resolved, msg.path)
+	if m.pane == modePreview {
+		m.loadPreview()
+	}
+	m.statusMsg = "validating " + filepath.Base(msg.path) + "..."
+	var files []composeFile
+	for _, f := range m.files {
+		if f.path == msg.path {
+			files = append(files, f)
+		}
+	}
+	return tea.Batch(validate(msg.path), loadMeta(files))
+}
+
+// validated offers to apply a valid file with up -d, or to reopen an
+// invalid one at the line the error points to.
+func (m *Model) validated(msg validatedMsg) {
+	target := ahab.Target{File: msg.path}
+	if msg.err == nil {
+		m.statusMsg = filepath.Base(msg.path) + " is valid"
+		m.openConfirm("start", []ahab.Target{target}, []string{"up", "-d"})
+		m.confirm.title = filepath.Base(msg.path) + " is valid. Apply it with up -d?"
+		return
+	}
+	line := 0
+	if match := errorLine.FindStringSubmatch(msg.err.Error()); match != nil {
+		line, _ = strconv.Atoi(match[1])
+	}
+	m.statusMsg = filepath.Base(msg.path) + " is invalid"
+	m.confirm = &confirmDialog{
+		title:    filepath.Base(msg.path) + " is invalid. Reopen it in the editor?",
+		message:  msg.err.Error(),
+		targets:  []ahab.Target{target},
+		editLine: max(line, 1),
+	}
+	if line > 0 {
+		m.confirm.title = fmt.Sprintf("%s is invalid. Reopen it at line %d?", filepath.Base(msg.path), line)
+	}
+}
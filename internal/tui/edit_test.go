@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_editorArgs(t *testing.T) {
+	tests := []struct {
+		editor []string
+		line   int
+		want   []string
+	}{
+		{[]string{"vim"}, 0, []string{"vim", "a.yaml"}},
+		{[]string{"nvim"}, 12, []string{"nvim", "+12", "a.yaml"}},
+		{[]string{"/usr/bin/code", "--wait"}, 3, []string{"/usr/bin/code", "--wait", "-g", "a.yaml:3"}},
+		{[]string{"subl", "-w"}, 7, []string{"subl", "-w", "a.yaml:7"}},
+	}
+	for _, tt := range tests {
+		if got := editorArgs(tt.editor, "a.yaml", tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("editorArgs(%v, %d) = %v, want %v", tt.editor, tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestModel_validated(t *testing.T) {
+	m := New(Options{})
+	m.validated(validatedMsg{path: "/docker/web/compose.yaml"})
+	if m.confirm == nil || m.confirm.action != "start" || m.confirm.editLine != 0 {
+		t.Fatalf("valid file: confirm = %+v, want an up -d prompt", m.confirm)
+	}
+
+	m.confirm = nil
+	m.validated(validatedMsg{path: "/docker/web/compose.yaml", err: errors.New("yaml: line 12: mapping values are not allowed in this context")})
+	if m.confirm == nil || m.confirm.editLine != 12 || m.confirm.action != "" {
+		t.Fatalf("invalid file: confirm = %+v, want a prompt to reopen at line 12", m.confirm)
+	}
+
+	m.confirm = nil
+	m.validated(validatedMsg{path: "/docker/web/compose.yaml", err: errors.New("services.web Additional property foo is not allowed")})
+	if m.confirm == nil || m.confirm.editLine != 1 {
+		t.Errorf("invalid file without a line: confirm = %+v, want a prompt to reopen at the top", m.confirm)
+	}
+}
@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// toastDuration is how long an error toast stays up unless dismissed.
+const toastDuration = 8 * time.Second
+
+// maxErrorHistory caps the number of errors kept for the error history.
+const maxErrorHistory = 200
+
+// errorEntry is one error, or log alert, in the session's error history.
+type errorEntry struct {
+	at     time.Time
+	stack  string
+	action string
+	err    string
+	alert  bool
+}
+
+type toastExpireMsg struct{ id int }
+
+// reportError records an error in the history, marks the stack it belongs to,
+// if any, and shows it in a toast.
+func (m *Model) reportError(stack, action string, err error) tea.Cmd {
+	text := err.Error()
+	if stack != "" {
+		// exit statuses say little on their own; add the last line of output.
+		if buf, ok := m.output[stack]; ok {
+			if lines := buf.get(); len(lines) > 0 {
+				text += ": " + lines[len(lines)-1]
+			}
+		}
+		m.stackErrs[stack] = text
+		m.resort()
+	}
+	return m.addHistory(errorEntry{at: time.Now(), stack: stack, action: action, err: text})
+}
+
+// reportAlert records a log alert in the history and shows it in a toast.
+func (m *Model) reportAlert(a ahab.Alert) tea.Cmd {
+	return m.addHistory(errorEntry{at: a.Time, err: a.String(), alert: true})
+}
+
+func (m *Model) addHistory(e errorEntry) tea.Cmd {
+	m.errHistory = append(m.errHistory, e)
+	if len(m.errHistory) > maxErrorHistory {
+		m.errHistory = m.errHistory[len(m.errHistory)-maxErrorHistory:]
+	}
+	m.toastID++
+	m.toast = e.icon() + " " + e.String()
+	id := m.toastID
+	return tea.Tick(toastDuration, func(time.Time) tea.Msg { return toastExpireMsg{id} })
+}
+
+func (e errorEntry) icon() string {
+	if e.alert {
+		return "⚠"
+	}
+	return "✗"
+}
+
+func (e errorEntry) String() string {
+	if e.alert {
+		return "alert: " + e.err
+	}
+	var b strings.Builder
+	if e.action != "" {
+		b.WriteString(e.action + " ")
+	}
+	if e.stack != "" {
+		b.WriteString(filepath.Base(filepath.Dir(e.stack)) + "/" + filepath.Base(e.stack) + " ")
+	}
+	b.WriteString("failed: " + e.err)
+	return b.String()
+}
+
+// updateErrors handles keys while the error history is open.
+func (m Model) updateErrors(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.keys.action(msg.String()) == "errors" {
+		m.showErrors = false
+		return m, nil
+	}
+	switch m.keys.in("errors", msg.String()) {
+	case "close":
+		m.showErrors = false
+	case "up":
+		if m.errCursor > 0 {
+			m.errCursor--
+		}
+	case "down":
+		if m.errCursor < len(m.errHistory)-1 {
+			m.errCursor++
+		}
+	case "clear":
+		m.errHistory = nil
+		m.stackErrs = make(map[string]string)
+		m.errCursor = 0
+	}
+	return m, nil
+}
+
+func (m Model) renderErrorsOverlay() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("errors (%d)", len(m.errHistory))) + "\n\n")
+	if len(m.errHistory) == 0 {
+		b.WriteString(dimStyle.Render("no errors this session") + "\n")
+	}
+	maxRows := max(m.height-10, 1)
+	start := 0
+	if m.errCursor >= maxRows {
+		start = m.errCursor - maxRows + 1
+	}
+	width := max(m.width-12, 20)
+	// Newest first.
+	for i := start; i < len(m.errHistory) && i < start+maxRows; i++ {
+		e := m.errHistory[len(m.errHistory)-1-i]
+		line := fmt.Sprintf("%s  %s %s", e.at.Local().Format("15:04:05"), e.icon(), e)
+		line = ansi.Truncate(line, width, "…")
+		if i == m.errCursor {
+			b.WriteString(selectedStyle.Render(line) + "\n")
+		} else {
+			b.WriteString(errorStyle.Render(line) + "\n")
+		}
+	}
+	b.WriteString("\n" + helpStyle.Render(m.keys.overlayHints("errors", "down", "up", "clear", "close")))
+	overlay := errorsOverlayStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, overlay)
+}
+
+func (m Model) renderToast() string {
+	var hints []string
+	if keys := m.keys.keys("clear"); len(keys) > 0 {
+		hints = append(hints, keys[0]+" to dismiss")
+	}
+	if keys := m.keys.keys("errors"); len(keys) > 0 {
+		hints = append(hints, keys[0]+" for history")
+	}
+	hint := strings.Join(hints, ", ")
+	return toastStyle.Width(m.width).Render(m.toast + "  (" + hint + ")")
+}
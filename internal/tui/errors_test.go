@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+func TestModel_setProgressErrors(t *testing.T) {
+	m := New(Options{})
+	m.outputFor("/docker/web/compose.yaml").append("Error response from daemon: no such image")
+
+	m.setProgress("start", ahab.FileProgress{File: "/docker/web/compose.yaml", Done: true, Err: errors.New("exit status 1")})
+	want := "exit status 1: Error response from daemon: no such image"
+	if got := m.stackErrs["/docker/web/compose.yaml"]; got != want {
+		t.Errorf("stackErrs = %q, want %q", got, want)
+	}
+	if len(m.errHistory) != 1 || m.toast == "" {
+		t.Errorf("expected one history entry and a toast, got %d entries, toast %q", len(m.errHistory), m.toast)
+	}
+	if m.state == stateError {
+		t.Errorf("action failure should not block the UI")
+	}
+
+	m.setProgress("start", ahab.FileProgress{File: "/docker/web/compose.yaml", Done: true})
+	if _, ok := m.stackErrs["/docker/web/compose.yaml"]; ok {
+		t.Errorf("successful action should clear the stack's error")
+	}
+	if len(m.errHistory) != 1 {
+		t.Errorf("history should keep past errors, got %d entries", len(m.errHistory))
+	}
+}
+
+func TestModel_reportAlert(t *testing.T) {
+	m := New(Options{})
+	m.reportAlert(ahab.Alert{Rule: "panic", Stack: "media", Service: "plex", Line: "panic: nil map"})
+	want := "⚠ alert: panic in media/plex: panic: nil map"
+	if m.toast != want {
+		t.Errorf("toast = %q, want %q", m.toast, want)
+	}
+	if len(m.errHistory) != 1 || !m.errHistory[0].alert {
+		t.Errorf("expected one alert in the history, got %+v", m.errHistory)
+	}
+	if len(m.stackErrs) != 0 {
+		t.Errorf("alerts should not mark stacks as failed")
+	}
+}
+
+func TestModel_renderErrorsOverlay(t *testing.T) {
+	m := New(Options{})
+	m.width, m.height = 40, 20
+	m.reportError("/docker/médiathèque/compose.yaml", "start", errors.New("échec: ✗ le conteneur n'a pas démarré à temps"))
+
+	view := m.renderErrorsOverlay()
+	if !utf8.ValidString(view) {
+		t.Errorf("overlay cut a multi-byte character:\n%s", view)
+	}
+	if !strings.Contains(view, "…") {
+		t.Errorf("long error was not truncated:\n%s", view)
+	}
+}
@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// eventRetryDelay is how long to wait before reconnecting to docker events.
+const eventRetryDelay = 5 * time.Second
+
+type eventsStartedMsg struct {
+	events <-chan ahab.ComposeEvent
+	cancel context.CancelFunc
+}
+type eventsClosedMsg struct{}
+type eventsRetryMsg struct{}
+type composeEventMsg struct{ event ahab.ComposeEvent }
+type fileStatusMsg struct {
+	path      string
+	status    string
+	unhealthy int
+}
+
+// watchEvents subscribes to the docker events stream for compose containers.
+func watchEvents() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithCancel(context.Background())
+		events, err := ahab.WatchComposeEvents(ctx)
+		if err != nil {
+			cancel()
+			return eventsClosedMsg{}
+		}
+		return eventsStartedMsg{events: events, cancel: cancel}
+	}
+}
+
+// waitForEvent blocks until the next compose event arrives.
+func waitForEvent(events <-chan ahab.ComposeEvent) tea.Cmd {
+	return func() tea.Msg {
+		ev, ok := <-events
+		if !ok {
+			return eventsClosedMsg{}
+		}
+		return composeEventMsg{ev}
+	}
+}
+
+// refreshStatus checks the status of a single compose file.
+func refreshStatus(path string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		state := ahab.GetComposeState(ctx, path)
+		return fileStatusMsg{path: path, status: state.Status, unhealthy: state.Unhealthy}
+	}
+}
+
+// handleComposeEvent refreshes the status of the stack an event belongs to.
+func (m *Model) handleComposeEvent(ev ahab.ComposeEvent) tea.Cmd {
+	idx := m.fileForConfigFiles(ev.ConfigFiles)
+	if idx < 0 {
+		// A project we don't know about changed; it may be an orphan.
+		return fetchOrphans()
+	}
+	name := ev.Container
+	if ev.Service != "" {
+		name = ev.Service
+	}
+	if ev.Health != "" {
+		m.statusMsg = fmt.Sprintf("%s: %s is %s", ev.Project, name, ev.Health)
+	} else {
+		m.statusMsg = fmt.Sprintf("%s: %s %s", ev.Project, name, eventVerb(ev.Action))
+	}
+	path := m.files[idx].path
+	if m.expanded[path] {
+		return tea.Batch(refreshStatus(path), fetchServiceStatuses(path))
+	}
+	return refreshStatus(path)
+}
+
+// fileForConfigFiles returns the index of the compose file matching one of
+// a container's config_files label entries, or -1.
+func (m Model) fileForConfigFiles(configFiles []string) int {
+	for _, cf := range configFiles {
+		for i, f := range m.files {
+			if sameFile(f.path, cf) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (m *Model) stopEvents() {
+	if m.eventCancel != nil {
+		m.eventCancel()
+		m.eventCancel = nil
+	}
+}
+
+func eventVerb(action string) string {
+	switch action {
+	case "start":
+		return "started"
+	case "stop":
+		return "stopped"
+	case "die":
+		return "died"
+	default:
+		return action
+	}
+}
+
+func sameFile(a, b string) bool {
+	if a == b {
+		return true
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
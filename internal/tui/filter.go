@@ -0,0 +1,219 @@
+package tui
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// statusFilters is the cycle order of the status filter; "" shows everything.
+var statusFilters = []string{"", "running", "partial", "stopped"}
+
+// metaSem bounds how many docker compose config commands run at once.
+var metaSem = make(chan struct{}, statusWorkers)
+
+type fileMetaMsg struct {
+	path string
+	meta ahab.ComposeMeta
+}
+
+// fileMatch is the best fuzzy match of the filter against one of a file's fields.
+type fileMatch struct {
+	score     int
+	field     string
+	text      string
+	positions []int
+}
+
+func newFilterInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "filter by path, project, service or tag"
+	return ti
+}
+
+// loadMeta resolves project names, services and tags for every file.
+func loadMeta(files []composeFile) tea.Cmd {
+	cmds := make([]tea.Cmd, len(files))
+	for i, f := range files {
+		path := f.path
+		cmds[i] = func() tea.Msg {
+			metaSem <- struct{}{}
+			defer func() { <-metaSem }()
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			meta, err := ahab.GetComposeMeta(ctx, path)
+			if err != nil {
+				return nil
+			}
+			return fileMetaMsg{path: path, meta: meta}
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *Model) setFileMeta(msg fileMetaMsg) {
+	for i := range m.files {
+		if m.files[i].path == msg.path {
+			m.files[i].meta = msg.meta
+			m.files[i].metaLoaded = true
+		}
+	}
+}
+
+// visible returns the indexes of the files shown in the list, in list order.
+func (m Model) visible() []int {
+	order := m.order
+	if order == nil {
+		order = m.sortOrder()
+	}
+	var idx []int
+	for _, i := range order {
+		f := m.files[i]
+		if !m.matchesStatusFilter(f) {
+			continue
+		}
+		if _, ok := m.matchFile(f); !ok {
+			continue
+		}
+		idx = append(idx, i)
+	}
+	return idx
+}
+
+// matchFile fuzzy-matches the filter against a file's name, path, project,
+// services and tags, returning the best-scoring field.
+func (m Model) matchFile(f composeFile) (fileMatch, bool) {
+	pattern := m.filterInput.Value()
+	if pattern == "" {
+		return fileMatch{}, true
+	}
+	fields := []struct{ name, text string }{
+		{"name", filepath.Base(f.path)},
+		{"path", f.path},
+		{"project", f.meta.Project},
+	}
+	for _, s := range f.meta.Services {
+		fields = append(fields, struct{ name, text string }{"service", s})
+	}
+	for _, t := range f.meta.Tags {
+		fields = append(fields, struct{ name, text string }{"tag", t})
+	}
+
+	best := fileMatch{score: -1}
+	for _, fld := range fields {
+		if score, pos, ok := fuzzyMatch(pattern, fld.text); ok && score > best.score {
+			best = fileMatch{score: score, field: fld.name, text: fld.text, positions: pos}
+		}
+	}
+	return best, best.score >= 0
+}
+
+// fuzzyMatch reports whether every rune of pattern appears in text in order,
+// ignoring case. It returns a score, higher for consecutive runs and matches
+// at word starts, and the rune positions of the matched characters.
+func fuzzyMatch(pattern, text string) (int, []int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(text))
+	var positions []int
+	score, pi := 0, 0
+	for ti := 0; ti < len(t) && pi < len(p); ti++ {
+		if t[ti] != p[pi] {
+			continue
+		}
+		score++
+		if n := len(positions); n > 0 && positions[n-1] == ti-1 {
+			score += 5
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 3
+		}
+		positions = append(positions, ti)
+		pi++
+	}
+	if pi < len(p) {
+		return 0, nil, false
+	}
+	return score, positions, true
+}
+
+// highlight renders text with the runes at positions emphasised.
+func highlight(text string, positions []int) string {
+	if len(positions) == 0 {
+		return text
+	}
+	matched := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		matched[p] = true
+	}
+	var b strings.Builder
+	for i, r := range []rune(text) {
+		if matched[i] {
+			b.WriteString(matchStyle.Render(string(r)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// matchesStatusFilter reports whether a file passes the status filter, which
+// can also select unhealthy stacks and stacks with image updates.
+func (m Model) matchesStatusFilter(f composeFile) bool {
+	switch m.statusFilter {
+	case "":
+		return true
+	case "unhealthy":
+		return f.unhealthy > 0
+	case "updates":
+		return len(m.updates[f.path]) > 0
+	default:
+		return f.status == m.statusFilter
+	}
+}
+
+// cycleStatusFilter advances to the next status filter. The filters only
+// set from the summary bar are followed by showing everything.
+func (m *Model) cycleStatusFilter() {
+	next := ""
+	for i, s := range statusFilters {
+		if s == m.statusFilter {
+			next = statusFilters[(i+1)%len(statusFilters)]
+			break
+		}
+	}
+	m.statusFilter = next
+	m.clampCursor()
+}
+
+// updateFilter handles keys while the filter prompt is open.
+func (m Model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.shutdown()
+		return m, tea.Quit
+	case "esc":
+		m.filtering = false
+		m.filterInput.Blur()
+		m.filterInput.SetValue("")
+		m.clampCursor()
+		m.selectionChanged()
+		return m, nil
+	case "enter":
+		m.filtering = false
+		m.filterInput.Blur()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	m.cursor = 0
+	m.clampCursor()
+	m.selectionChanged()
+	return m, cmd
+}
@@ -0,0 +1,87 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+func Test_fuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		text      string
+		wantOK    bool
+		wantMatch []int
+	}{
+		{"", "anything", true, nil},
+		{"trf", "traefik", true, []int{0, 1, 4}},
+		{"TRA", "traefik", true, []int{0, 1, 2}},
+		{"xyz", "traefik", false, nil},
+		{"kif", "traefik", false, nil},
+	}
+	for _, tt := range tests {
+		_, got, ok := fuzzyMatch(tt.pattern, tt.text)
+		if ok != tt.wantOK {
+			t.Errorf("fuzzyMatch(%q, %q) ok = %v, want %v", tt.pattern, tt.text, ok, tt.wantOK)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.wantMatch) {
+			t.Errorf("fuzzyMatch(%q, %q) positions = %v, want %v", tt.pattern, tt.text, got, tt.wantMatch)
+		}
+	}
+
+	consecutive, _, _ := fuzzyMatch("gra", "grafana")
+	scattered, _, _ := fuzzyMatch("gra", "gitea-runner-app")
+	if consecutive <= scattered {
+		t.Errorf("consecutive score %d should beat scattered score %d", consecutive, scattered)
+	}
+}
+
+func TestModel_visible(t *testing.T) {
+	m := New(Options{})
+	m.files = []composeFile{
+		{path: "/docker/traefik/compose.yaml", status: "running"},
+		{path: "/docker/media/compose.yaml", status: "stopped", meta: ahab.ComposeMeta{Services: []string{"plex"}}},
+		{path: "/docker/db/compose.yaml", status: "partial", meta: ahab.ComposeMeta{Tags: []string{"critical"}}},
+	}
+
+	m.filterInput.SetValue("plex")
+	if got, want := m.visible(), []int{1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("visible() by service = %v, want %v", got, want)
+	}
+
+	m.filterInput.SetValue("crit")
+	if got, want := m.visible(), []int{2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("visible() by tag = %v, want %v", got, want)
+	}
+
+	m.filterInput.SetValue("")
+	m.statusFilter = "stopped"
+	if got, want := m.visible(), []int{1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("visible() by status = %v, want %v", got, want)
+	}
+}
+
+func TestModel_updateFilter_selection(t *testing.T) {
+	m := treeModel()
+	m.state = stateList
+	m.flat = true
+	m.pane = modePreview
+	m.filtering = true
+	m.filterInput.Focus()
+
+	// The preview follows the row the filter leaves highlighted.
+	for _, r := range "traefik" {
+		next, _ := m.updateFilter(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = next.(Model)
+	}
+	if f, _ := m.selected(); f.path != "/docker/traefik/compose.yaml" {
+		t.Fatalf("selected %s, want traefik", f.path)
+	}
+	if !strings.Contains(m.preview, "/docker/traefik/compose.yaml") {
+		t.Errorf("preview = %q, want traefik's", m.preview)
+	}
+}
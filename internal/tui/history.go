@@ -0,0 +1,210 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// maxHistory caps the actions kept in the activity panel and history file.
+const maxHistory = 500
+
+type historyLoadedMsg struct {
+	entries []ahab.HistoryEntry
+	err     error
+}
+
+type historyErrMsg struct{ err error }
+
+// loadHistory reads the actions of previous sessions from the history file.
+func loadHistory() tea.Cmd {
+	return func() tea.Msg {
+		path, err := ahab.HistoryPath()
+		if err != nil {
+			return historyLoadedMsg{err: err}
+		}
+		entries, err := ahab.LoadHistory(path, maxHistory)
+		return historyLoadedMsg{entries: entries, err: err}
+	}
+}
+
+// setHistory puts the previous sessions' actions before this session's.
+func (m *Model) setHistory(msg historyLoadedMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.reportError("", "history", msg.err)
+	}
+	m.history = append(msg.entries, m.history...)
+	m.historyLoaded = len(msg.entries)
+	m.trimHistory()
+	m.resort()
+	return nil
+}
+
+// recordHistory adds a finished job to the history and appends it to the
+// history file.
+func (m *Model) recordHistory(j *job, result string, err error) tea.Cmd {
+	start := j.started
+	if start.IsZero() {
+		start = j.queued
+	}
+	e := ahab.HistoryEntry{
+		File:     j.target.File,
+		Services: j.target.Services,
+		Action:   j.action,
+		Args:     j.args,
+		Start:    start,
+		Duration: time.Since(start).Round(time.Millisecond),
+		Result:   result,
+		Output:   m.outputFor(j.target.File).since(j.output),
+	}
+	if err != nil && result == progressFailed {
+		e.Error = err.Error()
+	}
+	m.history = append(m.history, e)
+	m.trimHistory()
+	m.resort()
+	return func() tea.Msg {
+		path, err := ahab.HistoryPath()
+		if err == nil {
+			err = ahab.AppendHistory(path, e)
+		}
+		if err != nil {
+			return historyErrMsg{err}
+		}
+		return nil
+	}
+}
+
+func (m *Model) trimHistory() {
+	if n := len(m.history) - maxHistory; n > 0 {
+		m.history = m.history[n:]
+		m.historyLoaded = max(m.historyLoaded-n, 0)
+	}
+	m.historyCursor = min(m.historyCursor, max(len(m.history)-1, 0))
+}
+
+// selectedHistory returns the entry under the activity panel's cursor,
+// which counts from the newest entry.
+func (m Model) selectedHistory() (ahab.HistoryEntry, bool) {
+	if m.historyCursor >= len(m.history) {
+		return ahab.HistoryEntry{}, false
+	}
+	return m.history[len(m.history)-1-m.historyCursor], true
+}
+
+// rerun runs a history entry's action again on the same target, asking
+// first if the config says so.
+func (m *Model) rerun(e ahab.HistoryEntry) (tea.Model, tea.Cmd) {
+	m.showHistory = false
+	if !slices.ContainsFunc(m.files, func(f composeFile) bool { return f.path == e.File }) {
+		m.statusMsg = historyName(e) + " no longer exists"
+		return *m, nil
+	}
+	return m.requestTargets(e.Action, []ahab.Target{e.Target()}, e.Args...)
+}
+
+// updateHistory handles keys while the activity panel is open.
+func (m Model) updateHistory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.keys.action(msg.String()) == "history" {
+		m.showHistory = false
+		return m, nil
+	}
+	if msg.String() == "ctrl+c" {
+		m.shutdown()
+		return m, tea.Quit
+	}
+	switch m.keys.in("history", msg.String()) {
+	case "close":
+		m.showHistory = false
+	case "up":
+		if m.historyCursor > 0 {
+			m.historyCursor--
+		}
+	case "down":
+		if m.historyCursor < len(m.history)-1 {
+			m.historyCursor++
+		}
+	case "output":
+		m.historyOutput = !m.historyOutput
+	case "rerun":
+		if e, ok := m.selectedHistory(); ok {
+			return m.rerun(e)
+		}
+	}
+	return m, nil
+}
+
+// historyName labels an entry's target by its directory and file name.
+func historyName(e ahab.HistoryEntry) string {
+	return filepath.Base(filepath.Dir(e.File)) + "/" + targetName(e.Target())
+}
+
+func resultIcon(result string) string {
+	switch result {
+	case progressDone:
+		return successStyle.Render("✓")
+	case progressFailed:
+		return failureStyle.Render("✗")
+	default:
+		return warningStyle.Render("⊘")
+	}
+}
+
+func (m Model) renderHistoryOverlay() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("activity (%d)", len(m.history))) + "\n\n")
+	if len(m.history) == 0 {
+		b.WriteString(dimStyle.Render("no actions run yet") + "\n")
+	}
+	maxRows := max(m.height-10, 1)
+	if m.historyOutput {
+		maxRows = max(maxRows/2, 1)
+	}
+	start := 0
+	if m.historyCursor >= maxRows {
+		start = m.historyCursor - maxRows + 1
+	}
+	width := max(m.width-12, 20)
+	// Newest first; previous sessions are dimmed.
+	for i := start; i < len(m.history) && i < start+maxRows; i++ {
+		idx := len(m.history) - 1 - i
+		e := m.history[idx]
+		line := fmt.Sprintf("%s  %-8s %-32s %8s", e.Start.Local().Format("Jan 02 15:04:05"), e.Action,
+			historyName(e), e.Duration.Round(100*time.Millisecond))
+		if e.Error != "" {
+			line += "  " + e.Error
+		}
+		line = ansi.Truncate(line, width-2, "…")
+		switch {
+		case i == m.historyCursor:
+			line = selectedStyle.Render(line)
+		case idx < m.historyLoaded:
+			line = " " + helpStyle.Render(line)
+		default:
+			line = " " + line
+		}
+		b.WriteString(resultIcon(e.Result) + line + "\n")
+	}
+
+	if e, ok := m.selectedHistory(); ok && m.historyOutput {
+		b.WriteString("\n" + titleStyle.Render("output") + dimStyle.Render("docker compose "+strings.Join(e.Args, " ")) + "\n")
+		lines := e.Output
+		if len(lines) == 0 {
+			lines = []string{"(no output)"}
+		}
+		lines = lines[max(len(lines)-maxRows, 0):]
+		for _, line := range lines {
+			b.WriteString(logPrefixStyle.Render(ansi.Truncate(line, width, "…")) + "\n")
+		}
+	}
+	b.WriteString("\n" + helpStyle.Render(m.keys.overlayHints("history", "down", "up", "output", "rerun", "close")))
+	overlay := helpOverlayStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, overlay)
+}
@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+func TestModel_recordHistory(t *testing.T) {
+	m := New(Options{})
+	m.root = "/docker"
+	m.files = []composeFile{{path: "/docker/web/compose.yaml"}}
+	m.setHistory(historyLoadedMsg{entries: []ahab.HistoryEntry{{File: "/docker/web/compose.yaml", Action: "pull", Args: []string{"pull"}, Result: "done"}}})
+
+	out := m.outputFor("/docker/web/compose.yaml")
+	out.append("from an earlier action")
+	m.startJob("start", ahab.Target{File: "/docker/web/compose.yaml"}, "up", "-d")
+	out.append("Container web-app-1  Started")
+	m.setProgress("start", ahab.FileProgress{File: "/docker/web/compose.yaml", Done: true, Err: errors.New("exit status 1")})
+
+	if len(m.history) != 2 || m.historyLoaded != 1 {
+		t.Fatalf("history = %+v, loaded %d", m.history, m.historyLoaded)
+	}
+	e, _ := m.selectedHistory()
+	if e.Action != "start" || e.Result != progressFailed || e.Error != "exit status 1" {
+		t.Errorf("newest entry = %+v", e)
+	}
+	if want := []string{"Container web-app-1  Started"}; !reflect.DeepEqual(e.Output, want) {
+		t.Errorf("entry output = %q, want %q", e.Output, want)
+	}
+	if want := []string{"up", "-d"}; !reflect.DeepEqual(e.Args, want) {
+		t.Errorf("entry args = %q, want %q", e.Args, want)
+	}
+}
+
+func TestModel_rerun(t *testing.T) {
+	m := New(Options{Config: ahab.Config{Confirm: []string{"down"}}})
+	m.root = "/docker"
+	m.files = []composeFile{{path: "/docker/web/compose.yaml"}}
+	m.showHistory = true
+
+	m.rerun(ahab.HistoryEntry{File: "/docker/gone/compose.yaml", Action: "down", Args: []string{"down"}})
+	if m.confirm != nil || m.statusMsg != "gone/compose.yaml no longer exists" {
+		t.Errorf("rerun() of a missing stack: confirm %v, status %q", m.confirm, m.statusMsg)
+	}
+
+	m.rerun(ahab.HistoryEntry{File: "/docker/web/compose.yaml", Services: []string{"app"}, Action: "down", Args: []string{"down", "--volumes"}})
+	if m.showHistory {
+		t.Errorf("rerun() should close the activity panel")
+	}
+	if m.confirm == nil || !reflect.DeepEqual(m.confirm.args, []string{"down", "--volumes"}) ||
+		!reflect.DeepEqual(m.confirm.targets, []ahab.Target{{File: "/docker/web/compose.yaml", Services: []string{"app"}}}) {
+		t.Errorf("rerun() confirm = %+v", m.confirm)
+	}
+}
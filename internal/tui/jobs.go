@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// job is an action running, or queued to run, on one stack.
+type job struct {
+	action  string
+	args    []string
+	target  ahab.Target
+	queued  time.Time
+	started time.Time // zero while queued
+	output  int       // lines in the stack's output buffer before the job
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// startJob registers a job for a target and returns the context it runs with.
+func (m *Model) startJob(action string, t ahab.Target, args ...string) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.jobs[t.File] = &job{
+		action: action,
+		args:   args,
+		target: t,
+		queued: time.Now(),
+		output: m.outputFor(t.File).appended(),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	return ctx
+}
+
+// finishJob removes a stack's job and reports whether it had been cancelled.
+func (m *Model) finishJob(path string) bool {
+	j, ok := m.jobs[path]
+	if !ok {
+		return false
+	}
+	delete(m.jobs, path)
+	cancelled := j.ctx.Err() != nil
+	j.cancel()
+	return cancelled
+}
+
+// cancelJobs cancels the jobs of the current targets.
+func (m *Model) cancelJobs() {
+	n := 0
+	for _, t := range m.targets() {
+		if j, ok := m.jobs[t.File]; ok {
+			j.cancel()
+			n++
+		}
+	}
+	if n == 0 {
+		m.statusMsg = "no running jobs to cancel"
+		return
+	}
+	m.statusMsg = fmt.Sprintf("cancelling %d job(s)...", n)
+}
+
+// cancelAllJobs cancels every in-flight job.
+func (m *Model) cancelAllJobs() {
+	for _, j := range m.jobs {
+		j.cancel()
+	}
+}
+
+// startSpinner starts the spinner animation unless it is already running.
+func (m *Model) startSpinner() tea.Cmd {
+	if m.spinning {
+		return nil
+	}
+	m.spinning = true
+	return m.spinner.Tick
+}
+
+// renderJob renders a row's job as a spinner and the time it has been running.
+func (m Model) renderJob(j *job) string {
+	if j.started.IsZero() {
+		return progressIndicator(progressQueued)
+	}
+	elapsed := time.Since(j.started).Truncate(time.Second)
+	return jobStyle.Render(fmt.Sprintf("%s %s %s", m.spinner.View(), j.action, elapsed))
+}
@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+func TestModel_cancelJobs(t *testing.T) {
+	m := New(Options{})
+	m.root = "/"
+	m.files = []composeFile{{path: "/a.yaml"}, {path: "/b.yaml"}}
+	m.startJob("pull", ahab.Target{File: "/a.yaml"})
+	m.startJob("pull", ahab.Target{File: "/b.yaml"})
+
+	m.cursor = 0
+	m.cancelJobs()
+	if m.jobs["/a.yaml"].ctx.Err() == nil {
+		t.Fatalf("cancelJobs() did not cancel the selected stack's job")
+	}
+	if m.jobs["/b.yaml"].ctx.Err() != nil {
+		t.Fatalf("cancelJobs() cancelled a job that was not targeted")
+	}
+
+	m.setProgress("pull", ahab.FileProgress{File: "/a.yaml", Done: true, Err: errors.New("signal: killed")})
+	if got := m.progress["/a.yaml"]; got != progressCancelled {
+		t.Errorf("progress after cancel = %q, want %q", got, progressCancelled)
+	}
+	if _, ok := m.stackErrs["/a.yaml"]; ok {
+		t.Errorf("a cancelled job should not be reported as an error")
+	}
+	if _, ok := m.jobs["/a.yaml"]; ok {
+		t.Errorf("finished job should be removed")
+	}
+	if _, ok := m.jobs["/b.yaml"]; !ok {
+		t.Errorf("other job should still be tracked")
+	}
+}
@@ -0,0 +1,278 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// binding is a stack list action and the keys that run it.
+type binding struct {
+	action string
+	keys   []string
+	help   string
+}
+
+// defaultBindings are the stack list's keys, in the order the help overlay
+// lists them.
+var defaultBindings = []binding{
+	{"cursor-up", []string{"up", "k"}, "move up"},
+	{"cursor-down", []string{"down", "j"}, "move down"},
+	{"pane-info", []string{"1"}, "info pane"},
+	{"pane-preview", []string{"tab", "2"}, "preview pane"},
+	{"pane-logs", []string{"3"}, "logs pane"},
+	{"pane-stats", []string{"4"}, "stats pane"},
+	{"pane-output", []string{"5", "o"}, "action output"},
+	{"start", []string{"s"}, "start"},
+	{"stop", []string{"x"}, "stop"},
+	{"down", []string{"d"}, "down"},
+	{"down-options", []string{"D"}, "down with options (volumes, orphans, images)"},
+	{"restart", []string{"r"}, "restart"},
+	{"pull", []string{"p"}, "pull"},
+	{"recreate", []string{"R"}, "recreate (up -d --force-recreate)"},
+	{"cancel", []string{"c"}, "cancel running jobs"},
+	{"mark", []string{" "}, "mark/unmark file"},
+	{"mark-all", []string{"a"}, "mark all"},
+	{"mark-visible", []string{"A"}, "mark all matching filter"},
+	{"toggle", []string{"enter"}, "expand/collapse folder or services"},
+	{"collapse", []string{"left", "h"}, "collapse"},
+	{"expand", []string{"right"}, "expand"},
+	{"tree", []string{"t"}, "toggle tree/flat view"},
+	{"filter", []string{"/"}, "fuzzy filter"},
+	{"status-filter", []string{"f"}, "cycle status filter"},
+	{"summary", []string{"F"}, "filter by a count in the summary bar"},
+	{"sort", []string{"S"}, "cycle sort order (path, name, status, last action, memory, updates)"},
+	{"clear", []string{"esc"}, "dismiss toast, clear marks, then filters"},
+	{"logs", []string{"l"}, "toggle logs"},
+	{"log-viewer", []string{"L"}, "log viewer (scroll, pause, search, filter by service)"},
+	{"resolved", []string{"v"}, "toggle resolved config (docker compose config)"},
+	{"edit", []string{"e"}, "edit in $EDITOR, then validate"},
+	{"shell", []string{"X"}, "shell in the selected service"},
+	{"palette", []string{":"}, "run a docker compose command (tab completes)"},
+	{"scroll-down", []string{"J"}, "scroll preview down"},
+	{"scroll-up", []string{"K"}, "scroll preview up"},
+	{"half-page-down", []string{"ctrl+d"}, "scroll preview down half a page"},
+	{"half-page-up", []string{"ctrl+u"}, "scroll preview up half a page"},
+	{"page-down", []string{"pgdown"}, "scroll preview down a page"},
+	{"page-up", []string{"pgup"}, "scroll preview up a page"},
+	{"errors", []string{"E"}, "error history"},
+	{"history", []string{"H"}, "activity history (re-run with r)"},
+	{"help", []string{"?"}, "toggle help"},
+	{"quit", []string{"q", "ctrl+c"}, "quit"},
+}
+
+// overlayBindings are the keys of the overlays, named "overlay.action".
+// Their help is the short label shown in each overlay's hint line.
+var overlayBindings = []binding{
+	{"confirm.yes", []string{"y", "enter"}, "confirm"},
+	{"confirm.no", []string{"n", "esc", "q"}, "cancel"},
+	{"confirm.up", []string{"up", "k"}, "up"},
+	{"confirm.down", []string{"down", "j"}, "down"},
+	{"confirm.toggle", []string{" "}, "toggle option"},
+	{"errors.up", []string{"up", "k"}, "up"},
+	{"errors.down", []string{"down", "j"}, "down"},
+	{"errors.clear", []string{"c"}, "clear"},
+	{"errors.close", []string{"esc", "q"}, "close"},
+	{"history.up", []string{"up", "k"}, "up"},
+	{"history.down", []string{"down", "j"}, "down"},
+	{"history.output", []string{"enter", "o"}, "output"},
+	{"history.rerun", []string{"r"}, "re-run"},
+	{"history.close", []string{"esc", "q"}, "close"},
+	{"logs.up", []string{"up", "k"}, "up"},
+	{"logs.down", []string{"down", "j"}, "down"},
+	{"logs.half-page-up", []string{"pgup", "ctrl+u"}, "half page up"},
+	{"logs.half-page-down", []string{"pgdown", "ctrl+d"}, "half page down"},
+	{"logs.oldest", []string{"g", "home"}, "oldest"},
+	{"logs.newest", []string{"G", "end"}, "newest"},
+	{"logs.pause", []string{" ", "F"}, "pause"},
+	{"logs.search", []string{"/"}, "search"},
+	{"logs.next-match", []string{"n"}, "older match"},
+	{"logs.prev-match", []string{"N"}, "newer match"},
+	{"logs.service", []string{"s"}, "service"},
+	{"logs.timestamps", []string{"t"}, "timestamps"},
+	{"logs.close", []string{"esc", "q"}, "close"},
+	{"summary.left", []string{"left", "h"}, "left"},
+	{"summary.right", []string{"right", "l"}, "right"},
+	{"summary.select", []string{"enter", " "}, "filter"},
+	{"summary.close", []string{"esc", "q"}, "close"},
+}
+
+// requiredActions must keep a key, so that every overlay can be left and
+// the error toast dismissed.
+var requiredActions = []string{
+	"quit", "clear", "confirm.yes", "confirm.no", "errors.close", "history.close", "logs.close", "summary.close",
+}
+
+// keyMap maps keys to the actions of the stack list and the overlays.
+type keyMap struct {
+	bindings []binding
+	// actions maps each overlay's keys, or the stack list's under "", to
+	// action names without the overlay prefix.
+	actions map[string]map[string]string
+}
+
+// newKeyMap returns the default keymap with actions rebound by custom. A key
+// bound by custom is taken away from the action it ran by default in the
+// same overlay, or in the stack list. Unknown actions are skipped, and they
+// and required actions left without a key are reported in the error.
+func newKeyMap(custom map[string][]string) (keyMap, error) {
+	k := keyMap{actions: make(map[string]map[string]string)}
+	all := append(append([]binding{}, defaultBindings...), overlayBindings...)
+	known := make(map[string]bool)
+	for _, b := range all {
+		known[b.action] = true
+	}
+	var unknown []string
+	for action := range custom {
+		if !known[action] {
+			unknown = append(unknown, action)
+		}
+	}
+
+	taken := make(map[string]bool)
+	for action, keys := range custom {
+		overlay, _ := splitAction(action)
+		for _, key := range keys {
+			taken[overlay+"\x00"+normalizeKey(key)] = true
+		}
+	}
+	for _, b := range all {
+		overlay, name := splitAction(b.action)
+		keys, ok := custom[b.action]
+		if ok {
+			b.keys = nil
+			for _, key := range keys {
+				b.keys = append(b.keys, normalizeKey(key))
+			}
+		} else {
+			var kept []string
+			for _, key := range b.keys {
+				if !taken[overlay+"\x00"+key] {
+					kept = append(kept, key)
+				}
+			}
+			b.keys = kept
+		}
+		if k.actions[overlay] == nil {
+			k.actions[overlay] = make(map[string]string)
+		}
+		for _, key := range b.keys {
+			k.actions[overlay][key] = name
+		}
+		k.bindings = append(k.bindings, b)
+	}
+
+	var errs []string
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		errs = append(errs, "unknown action "+strings.Join(unknown, ", "))
+	}
+	var unbound []string
+	for _, action := range requiredActions {
+		if len(k.keys(action)) == 0 {
+			unbound = append(unbound, action)
+		}
+	}
+	if len(unbound) > 0 {
+		errs = append(errs, "no key left for "+strings.Join(unbound, ", "))
+	}
+	if len(errs) > 0 {
+		return k, fmt.Errorf("keys: %s", strings.Join(errs, "; "))
+	}
+	return k, nil
+}
+
+// splitAction splits "logs.search" into its overlay and name. Stack list
+// actions have no overlay.
+func splitAction(action string) (overlay, name string) {
+	if overlay, name, ok := strings.Cut(action, "."); ok {
+		return overlay, name
+	}
+	return "", action
+}
+
+// normalizeKey accepts "space" for the key bubbletea names " ".
+func normalizeKey(key string) string {
+	if key == "space" {
+		return " "
+	}
+	return key
+}
+
+// action returns the stack list action a key runs, or "" if it isn't bound.
+func (k keyMap) action(key string) string {
+	return k.actions[""][key]
+}
+
+// in returns the action a key runs in an overlay, without the overlay
+// prefix, or "" if it isn't bound there.
+func (k keyMap) in(overlay, key string) string {
+	return k.actions[overlay][key]
+}
+
+// keys returns the names of the keys bound to an action.
+func (k keyMap) keys(action string) []string {
+	var names []string
+	for _, b := range k.bindings {
+		if b.action != action {
+			continue
+		}
+		for _, key := range b.keys {
+			if key == " " {
+				key = "space"
+			}
+			names = append(names, key)
+		}
+	}
+	return names
+}
+
+// hints returns a one-line reminder of the first key of each action, for
+// the actions that have one.
+func (k keyMap) hints(actions ...string) string {
+	var hints []string
+	for _, action := range actions {
+		if keys := k.keys(action); len(keys) > 0 {
+			hints = append(hints, keys[0]+" "+action)
+		}
+	}
+	return strings.Join(hints, "  ")
+}
+
+// overlayHints is hints for an overlay's actions, labelled with their help.
+func (k keyMap) overlayHints(overlay string, names ...string) string {
+	var hints []string
+	for _, name := range names {
+		action := overlay + "." + name
+		keys := k.keys(action)
+		if len(keys) == 0 {
+			continue
+		}
+		for _, b := range k.bindings {
+			if b.action == action {
+				hints = append(hints, keys[0]+" "+b.help)
+			}
+		}
+	}
+	return strings.Join(hints, "  ")
+}
+
+// helpLines lists every bound stack list action and its keys.
+func (k keyMap) helpLines() []string {
+	var lines []string
+	width := 0
+	for _, b := range k.bindings {
+		if overlay, _ := splitAction(b.action); overlay == "" {
+			width = max(width, len(strings.Join(k.keys(b.action), "/")))
+		}
+	}
+	for _, b := range k.bindings {
+		if overlay, _ := splitAction(b.action); overlay != "" {
+			continue
+		}
+		if keys := k.keys(b.action); len(keys) > 0 {
+			lines = append(lines, fmt.Sprintf("%-*s  %s", width, strings.Join(keys, "/"), b.help))
+		}
+	}
+	return lines
+}
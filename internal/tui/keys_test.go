@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+func Test_newKeyMap(t *testing.T) {
+	k, err := newKeyMap(map[string][]string{
+		"start":  {"S"},
+		"shell":  {"s"},
+		"mark":   {"space", "m"},
+		"errors": {},
+	})
+	if err != nil {
+		t.Fatalf("newKeyMap() error = %v", err)
+	}
+	tests := map[string]string{
+		"S":      "start",
+		"s":      "shell",
+		"X":      "",
+		" ":      "mark",
+		"m":      "mark",
+		"E":      "",
+		"q":      "quit",
+		"ctrl+c": "quit",
+	}
+	for key, want := range tests {
+		if got := k.action(key); got != want {
+			t.Errorf("action(%q) = %q, want %q", key, got, want)
+		}
+	}
+	if got, want := k.keys("mark"), []string{"space", "m"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("keys(mark) = %v, want %v", got, want)
+	}
+	if got, want := k.hints("start", "errors", "quit"), "S start  q quit"; got != want {
+		t.Errorf("hints() = %q, want %q", got, want)
+	}
+
+	if _, err := newKeyMap(map[string][]string{"launch": {"l"}, "explode": {"x"}}); err == nil || !strings.Contains(err.Error(), "explode, launch") {
+		t.Errorf("newKeyMap() with unknown actions error = %v", err)
+	}
+}
+
+func Test_keyMap_helpLines(t *testing.T) {
+	k, _ := newKeyMap(map[string][]string{"shell": {"!"}, "errors": {}})
+	help := strings.Join(k.helpLines(), "\n")
+	for _, want := range []string{"!", "shell in the selected service", "up/k", "q/ctrl+c"} {
+		if !strings.Contains(help, want) {
+			t.Errorf("help is missing %q:\n%s", want, help)
+		}
+	}
+	if strings.Contains(help, "error history") {
+		t.Errorf("help lists an unbound action:\n%s", help)
+	}
+}
+
+func Test_newKeyMap_overlays(t *testing.T) {
+	k, err := newKeyMap(map[string][]string{
+		"logs.search":  {"?"},
+		"confirm.yes":  {"Y"},
+		"logs.service": {"/"},
+	})
+	if err != nil {
+		t.Fatalf("newKeyMap() error = %v", err)
+	}
+	tests := []struct {
+		overlay, key, want string
+	}{
+		{"logs", "?", "search"},
+		{"logs", "/", "service"},
+		{"logs", "s", ""},
+		{"confirm", "Y", "yes"},
+		{"confirm", "y", ""},
+		{"confirm", "n", "no"},
+		// Keys are only taken within the same overlay.
+		{"errors", "?", ""},
+		{"history", "r", "rerun"},
+	}
+	for _, tt := range tests {
+		if got := k.in(tt.overlay, tt.key); got != tt.want {
+			t.Errorf("in(%q, %q) = %q, want %q", tt.overlay, tt.key, got, tt.want)
+		}
+	}
+	if got := k.action("?"); got != "help" {
+		t.Errorf("action(?) = %q, want help", got)
+	}
+	if got, want := k.overlayHints("logs", "search", "close"), "? search  esc close"; got != want {
+		t.Errorf("overlayHints() = %q, want %q", got, want)
+	}
+
+	_, err = newKeyMap(map[string][]string{"help": {"esc"}, "logs.close": {}})
+	if err == nil || !strings.Contains(err.Error(), "no key left for clear, logs.close") {
+		t.Errorf("newKeyMap() leaving required actions unbound error = %v", err)
+	}
+}
+
+func TestModel_overlayKeys(t *testing.T) {
+	m := New(Options{Config: ahab.Config{Keys: map[string][]string{"confirm.yes": {"Y"}, "errors.close": {"x"}}}})
+	m.openConfirm("stop", []ahab.Target{{File: "/web.yaml"}}, []string{"stop"})
+	next, _ := m.updateConfirm(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if next.(Model).confirm == nil {
+		t.Error("y still confirms after confirm.yes was rebound")
+	}
+
+	m.confirm = nil
+	m.showErrors = true
+	next, _ = m.updateErrors(tea.KeyMsg{Type: tea.KeyEsc})
+	if !next.(Model).showErrors {
+		t.Error("esc still closes the errors overlay after errors.close was rebound")
+	}
+	next, _ = m.updateErrors(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if next.(Model).showErrors {
+		t.Error("x did not close the errors overlay")
+	}
+}
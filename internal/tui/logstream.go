@@ -1,11 +1,14 @@
 package tui
 
 import (
-	"bufio"
 	"context"
 	"io"
 	"os/exec"
+	"strconv"
 	"sync"
+	"time"
+
+	ahab "github.com/josh-allan/ahab/pkg"
 )
 
 // logBuffer is a thread-safe circular buffer for storing log lines.
@@ -14,6 +17,7 @@ type logBuffer struct {
 	lines  []string
 	size   int
 	offset int
+	total  int // lines ever appended
 }
 
 // newLogBuffer creates a new logBuffer with the given capacity.
@@ -25,6 +29,7 @@ func newLogBuffer(size int) *logBuffer {
 func (b *logBuffer) append(line string) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
+	b.total++
 	if len(b.lines) < b.size {
 		b.lines = append(b.lines, line)
 	} else {
@@ -37,6 +42,10 @@ func (b *logBuffer) append(line string) {
 func (b *logBuffer) get() []string {
 	b.mu.Lock()
 	defer b.mu.Unlock()
+	return b.ordered()
+}
+
+func (b *logBuffer) ordered() []string {
 	if len(b.lines) < b.size {
 		out := make([]string, len(b.lines))
 		copy(out, b.lines)
@@ -49,25 +58,79 @@ func (b *logBuffer) get() []string {
 	return out
 }
 
+// appended returns the number of lines ever appended to the buffer.
+func (b *logBuffer) appended() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.total
+}
+
+// since returns the lines appended after the buffer held total lines, or
+// as many of them as it still keeps.
+func (b *logBuffer) since(total int) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	lines := b.ordered()
+	return lines[max(len(lines)-(b.total-total), 0):]
+}
+
+// full reports whether the buffer has started dropping its oldest lines.
+func (b *logBuffer) full() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.total > b.size
+}
+
+// logTail is how many lines a new log stream starts with.
+const logTail = 200
+
+// logOptions narrows what a logStreamer fetches.
+type logOptions struct {
+	service string    // only this service, or all when empty
+	since   time.Time // history from this time instead of the last logTail lines
+}
+
 // logStreamer runs "docker compose logs -f" and stores output in a ring buffer.
+// Lines are always fetched with timestamps; the viewer decides whether to show them.
 type logStreamer struct {
+	file   string
+	opts   logOptions
 	cmd    *exec.Cmd
 	cancel context.CancelFunc
 	buffer *logBuffer
+	onLine func(string)  // called with each line, if set
+	done   chan struct{} // closed once the stream ends
 }
 
-// startLogStreamer creates a new logStreamer for the given compose file.
-func startLogStreamer(file string) *logStreamer {
+// startLogStreamer creates a new logStreamer for the given compose file,
+// keeping up to size lines.
+func startLogStreamer(file string, size int, opts logOptions) *logStreamer {
 	ctx, cancel := context.WithCancel(context.Background())
-	cmd := exec.CommandContext(ctx, "docker", "compose", "-f", file, "logs", "-f", "--tail", "100")
+	cmd := exec.CommandContext(ctx, "docker", logArgs(file, size, opts)...)
 	cmd.Stderr = io.Discard
 	return &logStreamer{
+		file:   file,
+		opts:   opts,
 		cmd:    cmd,
 		cancel: cancel,
-		buffer: newLogBuffer(100),
+		buffer: newLogBuffer(size),
+		done:   make(chan struct{}),
 	}
 }
 
+func logArgs(file string, size int, opts logOptions) []string {
+	args := []string{"compose", "-f", file, "logs", "-f", "--timestamps"}
+	if opts.since.IsZero() {
+		args = append(args, "--tail", strconv.Itoa(min(logTail, size)))
+	} else {
+		args = append(args, "--since", opts.since.UTC().Format(time.RFC3339))
+	}
+	if opts.service != "" {
+		args = append(args, opts.service)
+	}
+	return args
+}
+
 // run starts the log command and begins reading output into the buffer.
 func (ls *logStreamer) run() error {
 	stdout, err := ls.cmd.StdoutPipe()
@@ -81,15 +144,15 @@ func (ls *logStreamer) run() error {
 	go func() {
 		_ = ls.cmd.Wait()
 	}()
-	scanner := bufio.NewScanner(stdout)
-	const maxCapacity = 512 * 1024 // 512KB
-	buf := make([]byte, maxCapacity)
-	scanner.Buffer(buf, maxCapacity)
 	go func() {
-		for scanner.Scan() {
-			ls.buffer.append(scanner.Text())
-		}
-		if err := scanner.Err(); err != nil {
+		defer close(ls.done)
+		err := ahab.ReadLines(stdout, func(line string) {
+			ls.buffer.append(line)
+			if ls.onLine != nil {
+				ls.onLine(line)
+			}
+		})
+		if err != nil {
 			ls.buffer.append("[log stream error: " + err.Error() + "]")
 		}
 	}()
@@ -1,6 +1,10 @@
 package tui
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+	"time"
+)
 
 func Test_logBuffer(t *testing.T) {
 	b := newLogBuffer(3)
@@ -27,3 +31,24 @@ func Test_logBuffer(t *testing.T) {
 		}
 	}
 }
+
+func Test_logArgs(t *testing.T) {
+	since := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		size int
+		opts logOptions
+		want []string
+	}{
+		{"tail", 2000, logOptions{}, []string{"compose", "-f", "a.yaml", "logs", "-f", "--timestamps", "--tail", "200"}},
+		{"small buffer", 50, logOptions{}, []string{"compose", "-f", "a.yaml", "logs", "-f", "--timestamps", "--tail", "50"}},
+		{"since and service", 2000, logOptions{service: "web", since: since}, []string{"compose", "-f", "a.yaml", "logs", "-f", "--timestamps", "--since", "2024-05-01T10:00:00Z", "web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logArgs("a.yaml", tt.size, tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("logArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,393 @@
+package tui
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// logView is the full-screen log viewer. While paused it stays anchored to
+// the timestamp of its bottom line, so new lines and reloads of older
+// history don't move what is on screen.
+type logView struct {
+	follow     bool
+	anchor     time.Time // bottom visible line while paused
+	timestamps bool
+	search     *regexp.Regexp
+	searching  bool
+	input      textinput.Model
+	match      time.Time // current search match
+}
+
+// logLevel matches common log level markers, in upper case or as level=.
+var logLevel = regexp.MustCompile(`\b(FATAL|PANIC|ERROR|ERR|WARN|WARNING|INFO|DEBUG|TRACE)\b|\blevel=(fatal|panic|error|warn|warning|info|debug|trace)\b`)
+
+func newSearchInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "search: "
+	ti.Placeholder = "regexp"
+	ti.CharLimit = 256
+	return ti
+}
+
+// openLogView opens the log viewer on the selected file's log stream.
+func (m *Model) openLogView() tea.Cmd {
+	f, ok := m.selected()
+	if !ok {
+		return nil
+	}
+	if m.logStreamer == nil || m.logStreamer.file != f.path {
+		m.restartLogStreamer()
+	}
+	m.logView = &logView{follow: true, input: newSearchInput()}
+	return m.logTickCmd()
+}
+
+func (m *Model) closeLogView() {
+	m.logView = nil
+	if m.pane != modeLogs {
+		m.stopLogStreamer()
+	}
+}
+
+// logViewHeight is the number of log lines the viewer shows.
+func (m Model) logViewHeight() int {
+	return max(m.height-4, 1)
+}
+
+// logLines returns the parsed lines of the current log stream.
+func (m Model) logLines() []ahab.LogLine {
+	if m.logStreamer == nil {
+		return nil
+	}
+	raw := m.logStreamer.buffer.get()
+	lines := make([]ahab.LogLine, len(raw))
+	for i, line := range raw {
+		lines[i] = ahab.ParseLogLine(line)
+	}
+	return lines
+}
+
+// logOffset returns how many lines below the view are hidden.
+func (lv *logView) logOffset(lines []ahab.LogLine) int {
+	if lv.follow || lv.anchor.IsZero() {
+		return 0
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		if t := lines[i].Time; !t.IsZero() && !t.After(lv.anchor) {
+			return len(lines) - 1 - i
+		}
+	}
+	return 0
+}
+
+// anchorAt pauses the view with line i at the bottom.
+func (lv *logView) anchorAt(lines []ahab.LogLine, i int) {
+	lv.follow = false
+	for ; i >= 0; i-- {
+		if !lines[i].Time.IsZero() {
+			lv.anchor = lines[i].Time
+			return
+		}
+	}
+}
+
+// scrollLogs moves the view up by delta lines, or down if delta is negative.
+// Scrolling up past the oldest line loads older history, and scrolling back
+// to the newest line resumes following.
+func (m *Model) scrollLogs(delta int) {
+	lv := m.logView
+	lines := m.logLines()
+	h := m.logViewHeight()
+	top := max(len(lines)-h, 0)
+	off := lv.logOffset(lines)
+	if delta > 0 && off >= top {
+		m.loadOlderLogs(lines)
+		return
+	}
+	off = min(max(off+delta, 0), top)
+	if off == 0 && delta < 0 {
+		lv.follow = true
+		lv.anchor = time.Time{}
+		return
+	}
+	if len(lines) > 0 {
+		lv.anchorAt(lines, len(lines)-1-off)
+	}
+}
+
+// loadOlderLogs restarts the log stream further back in time, roughly
+// doubling the history shown, unless the buffer is already full.
+func (m *Model) loadOlderLogs(lines []ahab.LogLine) {
+	ls := m.logStreamer
+	if ls == nil {
+		return
+	}
+	if ls.buffer.full() {
+		m.statusMsg = fmt.Sprintf("log buffer full (%d lines); raise log_buffer to keep more", ls.buffer.size)
+		return
+	}
+	var oldest, newest time.Time
+	for _, l := range lines {
+		if l.Time.IsZero() {
+			continue
+		}
+		if oldest.IsZero() {
+			oldest = l.Time
+		}
+		newest = l.Time
+	}
+	if oldest.IsZero() {
+		return
+	}
+	span := max(newest.Sub(oldest), time.Minute)
+	since := oldest.Add(-span)
+	if m.logView.follow || m.logView.anchor.IsZero() {
+		m.logView.anchorAt(lines, len(lines)-1)
+	}
+	m.streamLogs(logOptions{service: ls.opts.service, since: since})
+	m.statusMsg = "loading logs since " + since.Local().Format("15:04:05")
+}
+
+// findMatch moves to the next search match older than the current one, or
+// newer when older is false.
+func (m *Model) findMatch(older bool) {
+	lv := m.logView
+	if lv.search == nil {
+		return
+	}
+	lines := m.logLines()
+	start := len(lines)
+	if !lv.match.IsZero() {
+		for i, l := range lines {
+			if l.Time.Equal(lv.match) {
+				start = i
+				break
+			}
+		}
+	} else if !older {
+		start = -1
+	}
+	step := 1
+	if older {
+		step = -1
+	}
+	for i := start + step; i >= 0 && i < len(lines); i += step {
+		if lv.search.MatchString(ansi.Strip(lines[i].Prefix + lines[i].Text)) {
+			lv.match = lines[i].Time
+			lv.anchorAt(lines, min(i+m.logViewHeight()/2, len(lines)-1))
+			return
+		}
+	}
+	m.statusMsg = "no more matches"
+}
+
+// cycleLogService restarts the stream on the next service of the stack,
+// cycling back to all services.
+func (m *Model) cycleLogService() {
+	f, ok := m.selected()
+	if !ok || m.logStreamer == nil {
+		return
+	}
+	services := f.meta.Services
+	if len(services) == 0 {
+		m.statusMsg = "services not known yet"
+		return
+	}
+	next := services[0]
+	for i, s := range services {
+		if s == m.logStreamer.opts.service {
+			next = ""
+			if i+1 < len(services) {
+				next = services[i+1]
+			}
+			break
+		}
+	}
+	m.streamLogs(logOptions{service: next})
+	m.logView.follow = true
+	m.logView.anchor = time.Time{}
+	m.logView.match = time.Time{}
+}
+
+// updateLogView handles keys while the log viewer is open.
+func (m Model) updateLogView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	lv := m.logView
+	if lv.searching {
+		switch msg.String() {
+		case "enter":
+			lv.searching = false
+			lv.input.Blur()
+			if lv.input.Value() == "" {
+				lv.search = nil
+				return m, nil
+			}
+			re, err := regexp.Compile(lv.input.Value())
+			if err != nil {
+				m.statusMsg = "invalid search: " + err.Error()
+				return m, nil
+			}
+			lv.search = re
+			lv.match = time.Time{}
+			m.findMatch(true)
+			return m, nil
+		case "esc":
+			lv.searching = false
+			lv.input.Blur()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		lv.input, cmd = lv.input.Update(msg)
+		return m, cmd
+	}
+
+	h := m.logViewHeight()
+	if msg.String() == "ctrl+c" {
+		m.shutdown()
+		return m, tea.Quit
+	}
+	if m.keys.action(msg.String()) == "log-viewer" {
+		m.closeLogView()
+		return m, nil
+	}
+	switch m.keys.in("logs", msg.String()) {
+	case "close":
+		m.closeLogView()
+	case "up":
+		m.scrollLogs(1)
+	case "down":
+		m.scrollLogs(-1)
+	case "half-page-up":
+		m.scrollLogs(h / 2)
+	case "half-page-down":
+		m.scrollLogs(-h / 2)
+	case "oldest":
+		m.scrollLogs(len(m.logLines()))
+	case "newest":
+		lv.follow = true
+		lv.anchor = time.Time{}
+	case "pause":
+		if lv.follow {
+			lines := m.logLines()
+			if len(lines) > 0 {
+				lv.anchorAt(lines, len(lines)-1)
+			}
+		} else {
+			lv.follow = true
+			lv.anchor = time.Time{}
+		}
+	case "search":
+		lv.searching = true
+		return m, lv.input.Focus()
+	case "next-match":
+		m.findMatch(true)
+	case "prev-match":
+		m.findMatch(false)
+	case "service":
+		m.cycleLogService()
+	case "timestamps":
+		lv.timestamps = !lv.timestamps
+	}
+	return m, nil
+}
+
+// formatLogLine renders a log line, coloring its log level unless the line
+// brings its own colors.
+func formatLogLine(l ahab.LogLine, timestamps bool, search *regexp.Regexp) string {
+	var b strings.Builder
+	if l.Prefix != "" {
+		b.WriteString(logPrefixStyle.Render(l.Prefix))
+	}
+	if timestamps && !l.Time.IsZero() {
+		b.WriteString(logPrefixStyle.Render(l.Time.Local().Format("2006-01-02 15:04:05.000")) + " ")
+	}
+	text := l.Text
+	switch {
+	case strings.Contains(text, "\x1b["):
+	case search != nil && search.MatchString(text):
+		text = search.ReplaceAllStringFunc(text, func(s string) string { return matchStyle.Render(s) })
+	default:
+		text = colorLogLevel(text)
+	}
+	b.WriteString(text)
+	return b.String()
+}
+
+// colorLogLevel colors the first log level marker in text.
+func colorLogLevel(text string) string {
+	loc := logLevel.FindStringIndex(text)
+	if loc == nil {
+		return text
+	}
+	level := strings.ToLower(strings.TrimPrefix(text[loc[0]:loc[1]], "level="))
+	style := logInfoStyle
+	switch level {
+	case "fatal", "panic", "error", "err":
+		style = logErrorStyle
+	case "warn", "warning":
+		style = logWarnStyle
+	case "debug", "trace":
+		style = logDebugStyle
+	}
+	return text[:loc[0]] + style.Render(text[loc[0]:loc[1]]) + text[loc[1]:]
+}
+
+func (m Model) renderLogView() string {
+	lv := m.logView
+	var b strings.Builder
+	header := titleStyle.Render("logs")
+	if f, ok := m.selected(); ok {
+		header += dimStyle.Render(path.Join(m.relDir(f.path), filepath.Base(f.path)))
+	}
+	state := "following"
+	if !lv.follow {
+		state = "paused"
+	}
+	tags := []string{state}
+	if m.logStreamer != nil {
+		if s := m.logStreamer.opts.service; s != "" {
+			tags = append(tags, "service: "+s)
+		}
+		if !m.logStreamer.opts.since.IsZero() {
+			tags = append(tags, "since "+m.logStreamer.opts.since.Local().Format("15:04:05"))
+		}
+	}
+	if lv.search != nil {
+		tags = append(tags, "/"+lv.search.String()+"/")
+	}
+	b.WriteString(header + jobStyle.Render(strings.Join(tags, "  ")) + "\n")
+
+	h := m.logViewHeight()
+	lines := m.logLines()
+	if m.logStreamer == nil {
+		b.WriteString(dimStyle.Render("no log stream") + "\n")
+	}
+	end := len(lines) - lv.logOffset(lines)
+	start := max(end-h, 0)
+	width := max(m.width-1, 10)
+	for _, l := range lines[start:end] {
+		line := formatLogLine(l, lv.timestamps, lv.search)
+		if !lv.match.IsZero() && l.Time.Equal(lv.match) {
+			line = matchStyle.Render("▌") + line
+		}
+		b.WriteString(ansi.Truncate(line, width, "…") + "\n")
+	}
+	for i := end - start; i < h; i++ {
+		b.WriteString("\n")
+	}
+
+	if lv.searching {
+		b.WriteString(statusStyle.Render(lv.input.View()))
+	} else {
+		b.WriteString(helpStyle.Render(m.keys.overlayHints("logs", "down", "up", "pause", "oldest", "newest", "search", "next-match", "prev-match", "service", "timestamps", "close")))
+	}
+	return b.String()
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	ahab "github.com/josh-allan/ahab/pkg"
+	"github.com/muesli/termenv"
+)
+
+func Test_logView_logOffset(t *testing.T) {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	lines := []ahab.LogLine{
+		{Time: base, Text: "a"},
+		{Time: base.Add(time.Second), Text: "b"},
+		{Text: "no timestamp"},
+		{Time: base.Add(2 * time.Second), Text: "c"},
+	}
+
+	lv := &logView{follow: true}
+	if got := lv.logOffset(lines); got != 0 {
+		t.Errorf("following logOffset() = %d, want 0", got)
+	}
+
+	lv.anchorAt(lines, 2) // untimed line anchors to the timed line above it
+	if lv.follow || !lv.anchor.Equal(base.Add(time.Second)) {
+		t.Fatalf("anchorAt() = follow %v anchor %v", lv.follow, lv.anchor)
+	}
+	if got := lv.logOffset(lines); got != 2 {
+		t.Errorf("paused logOffset() = %d, want 2", got)
+	}
+
+	// Older history loaded above keeps the same lines at the bottom.
+	older := append([]ahab.LogLine{{Time: base.Add(-time.Minute), Text: "old"}}, lines...)
+	if got := lv.logOffset(older); got != 2 {
+		t.Errorf("logOffset() after reload = %d, want 2", got)
+	}
+}
+
+func Test_colorLogLevel(t *testing.T) {
+	tests := []struct {
+		text  string
+		level string
+	}{
+		{"2024 ERROR something broke", "ERROR"},
+		{"time=x level=warn msg=slow", "level=warn"},
+		{"all good", ""},
+		{"information only", ""},
+	}
+	for _, tt := range tests {
+		got := colorLogLevel(tt.text)
+		if tt.level == "" {
+			if got != tt.text {
+				t.Errorf("colorLogLevel(%q) = %q, want unchanged", tt.text, got)
+			}
+			continue
+		}
+		if loc := logLevel.FindStringIndex(tt.text); loc == nil || tt.text[loc[0]:loc[1]] != tt.level {
+			t.Errorf("logLevel in %q = %v, want %q", tt.text, loc, tt.level)
+		}
+	}
+
+	// Each level is styled without changing the text.
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	defer lipgloss.SetColorProfile(profile)
+	for _, text := range []string{
+		"2024 ERROR something broke",
+		"level=warn msg=slow",
+		"INFO listening on :8080",
+		"level=debug msg=tick",
+	} {
+		got := colorLogLevel(text)
+		if got == text {
+			t.Errorf("colorLogLevel(%q) is unstyled", text)
+		}
+		if plain := ansi.Strip(got); plain != text {
+			t.Errorf("colorLogLevel(%q) changed the text to %q", text, plain)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// maxOrphanRows caps how much of the list pane the orphans section may use.
+const maxOrphanRows = 5
+
+type orphansMsg struct {
+	orphans []ahab.ComposeProject
+	err     error
+}
+
+// fetchOrphans looks for running compose projects whose files are no longer discovered.
+func fetchOrphans() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		orphans, err := ahab.FindOrphans(ctx)
+		return orphansMsg{orphans: orphans, err: err}
+	}
+}
+
+// orphanRows is the number of list rows taken by the orphans section.
+func (m Model) orphanRows() int {
+	if len(m.orphans) == 0 {
+		return 0
+	}
+	return min(len(m.orphans), maxOrphanRows) + 2
+}
+
+func (m Model) renderOrphans() string {
+	if len(m.orphans) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n" + titleStyle.Render(fmt.Sprintf("orphans (%d)", len(m.orphans))) + "\n")
+	for i, p := range m.orphans {
+		if i == maxOrphanRows {
+			break
+		}
+		line := fmt.Sprintf("%s %s  %s", statusIndicator("orphan"), p.Name, p.Status)
+		b.WriteString(dimStyle.Render(line) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// outputLines is how many lines of action output are kept per stack.
+const outputLines = 200
+
+// lineWriter splits written bytes into lines and appends them to a logBuffer.
+// Carriage returns, used by progress output, also end a line.
+type lineWriter struct {
+	mu      sync.Mutex
+	buf     *logBuffer
+	partial []byte
+}
+
+func newLineWriter(buf *logBuffer) *lineWriter {
+	return &lineWriter{buf: buf}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexAny(w.partial, "\r\n")
+		if i < 0 {
+			break
+		}
+		if line := strings.TrimRight(string(w.partial[:i]), " "); line != "" {
+			w.buf.append(line)
+		}
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush appends any unterminated final line.
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if line := strings.TrimRight(string(w.partial), " "); line != "" {
+		w.buf.append(line)
+	}
+	w.partial = nil
+}
+
+// outputFor returns the output buffer of a stack, creating it if needed.
+func (m *Model) outputFor(path string) *logBuffer {
+	buf, ok := m.output[path]
+	if !ok {
+		buf = newLogBuffer(outputLines)
+		m.output[path] = buf
+	}
+	return buf
+}
+
+func (m Model) renderOutput(height int) string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("output") + "\n\n")
+	f, ok := m.selected()
+	if !ok {
+		b.WriteString(dimStyle.Render("  no file selected") + "\n")
+		return b.String()
+	}
+	buf, ok := m.output[f.path]
+	if !ok {
+		b.WriteString(dimStyle.Render("  no actions run yet") + "\n")
+		return b.String()
+	}
+	lines := buf.get()
+	maxLines := height - 3
+	start := 0
+	if len(lines) > maxLines {
+		start = len(lines) - maxLines
+	}
+	for _, line := range lines[start:] {
+		b.WriteString(logStyle.Render(line) + "\n")
+	}
+	return b.String()
+}
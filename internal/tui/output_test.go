@@ -0,0 +1,20 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_lineWriter(t *testing.T) {
+	buf := newLogBuffer(10)
+	w := newLineWriter(buf)
+	w.Write([]byte("Running: docker compose pull\n web Pul"))
+	w.Write([]byte("ling\r web Pulled\r\n\npartial"))
+	if got, want := buf.get(), []string{"Running: docker compose pull", " web Pulling", " web Pulled"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+	w.flush()
+	if got := buf.get(); got[len(got)-1] != "partial" {
+		t.Errorf("flush() last line = %q, want %q", got[len(got)-1], "partial")
+	}
+}
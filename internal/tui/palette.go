@@ -0,0 +1,215 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// composeCommands are the docker compose subcommands the palette completes.
+var composeCommands = []string{
+	"build", "config", "cp", "create", "down", "exec", "images", "kill", "ls",
+	"pause", "port", "ps", "pull", "push", "restart", "rm", "scale", "start",
+	"stats", "stop", "top", "unpause", "up", "version", "wait",
+}
+
+// unrunnableCommands stream until stopped or need a terminal, so their
+// output would never finish in the output pane.
+var unrunnableCommands = []string{"attach", "events", "logs", "run", "watch"}
+
+// commandFlags are added to subcommands that would otherwise stream or
+// want a terminal: the first flag is added unless one of them is given.
+var commandFlags = map[string][]string{
+	"exec":  {"-T", "--no-TTY"},
+	"stats": {"--no-stream"},
+	"up":    {"-d", "--detach", "--wait"},
+}
+
+// commandPalette is the prompt that runs a docker compose command on the
+// selected or marked stacks.
+type commandPalette struct {
+	input textinput.Model
+	// matches are the completions of the word being completed, cycled
+	// through by repeated tabs once their common prefix is typed.
+	matches []string
+	match   int
+}
+
+func newPaletteInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = ":"
+	ti.Placeholder = "docker compose command, e.g. ps or up -d --force-recreate"
+	return ti
+}
+
+// openPalette opens the command palette.
+func (m *Model) openPalette() tea.Cmd {
+	if len(m.targets()) == 0 {
+		return nil
+	}
+	m.palette = &commandPalette{input: newPaletteInput()}
+	return m.palette.input.Focus()
+}
+
+// updatePalette handles keys while the command palette is open.
+func (m Model) updatePalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.palette
+	switch msg.String() {
+	case "ctrl+c":
+		m.shutdown()
+		return m, tea.Quit
+	case "esc":
+		m.palette = nil
+		return m, nil
+	case "enter":
+		m.palette = nil
+		return m.runCommand(p.input.Value())
+	case "tab":
+		m.complete()
+		return m, nil
+	}
+	p.matches = nil
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return m, cmd
+}
+
+// complete completes the last word of the palette: a subcommand for the
+// first word, a service of the selected stacks after it. The first tab
+// completes as far as the matches agree, later ones cycle through them.
+func (m *Model) complete() {
+	p := m.palette
+	value := p.input.Value()
+	start, matches := m.completions()
+	if len(p.matches) > 1 {
+		p.match = (p.match + 1) % len(p.matches)
+		p.input.SetValue(value[:start] + p.matches[p.match])
+		p.input.CursorEnd()
+		return
+	}
+	switch len(matches) {
+	case 0:
+		return
+	case 1:
+		p.input.SetValue(value[:start] + matches[0] + " ")
+	default:
+		prefix := commonPrefix(matches)
+		if prefix == value[start:] {
+			p.matches = matches
+			p.match = -1
+		}
+		p.input.SetValue(value[:start] + prefix)
+	}
+	p.input.CursorEnd()
+}
+
+// completions returns where the palette's last word starts and what it can
+// be completed to.
+func (m Model) completions() (int, []string) {
+	value := m.palette.input.Value()
+	start := strings.LastIndex(value, " ") + 1
+	candidates := composeCommands
+	if strings.TrimSpace(value[:start]) != "" {
+		candidates = m.paletteServices()
+	}
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, value[start:]) {
+			matches = append(matches, c)
+		}
+	}
+	return start, matches
+}
+
+// paletteServices returns the services of the stacks the palette runs on.
+func (m Model) paletteServices() []string {
+	var names []string
+	for _, t := range m.targets() {
+		for _, f := range m.files {
+			if f.path != t.File {
+				continue
+			}
+			for _, s := range m.serviceNames(f) {
+				if !slices.Contains(names, s) {
+					names = append(names, s)
+				}
+			}
+		}
+	}
+	return names
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// runCommand runs a palette command on the selected or marked stacks and
+// shows its output. The command's first word names the action for confirm
+// rules and the activity panel. A selected service is passed to the
+// command unless the command names services of its own.
+func (m *Model) runCommand(line string) (tea.Model, tea.Cmd) {
+	args := strings.Fields(line)
+	if len(args) == 0 {
+		return *m, nil
+	}
+	if slices.Contains(unrunnableCommands, args[0]) {
+		m.statusMsg = fmt.Sprintf("%s can't run from the palette; try %s", args[0], m.keys.hints("log-viewer", "shell"))
+		return *m, nil
+	}
+	if flags, ok := commandFlags[args[0]]; ok && !slices.ContainsFunc(args, func(a string) bool { return slices.Contains(flags, a) }) {
+		args = slices.Insert(args, 1, flags[0])
+	}
+	services := m.paletteServices()
+	named := slices.ContainsFunc(args[1:], func(a string) bool { return slices.Contains(services, a) })
+	// exec takes its service before the command, where a selected service
+	// appended at the end can't go, so it has to be named.
+	if args[0] == "exec" && !named {
+		m.statusMsg = "exec needs a service: exec <service> <command>"
+		return *m, nil
+	}
+	var targets []ahab.Target
+	for _, t := range m.targets() {
+		if named {
+			t.Services = nil
+		}
+		targets = append(targets, t)
+	}
+	m.stopLogStreamer()
+	m.pane = modeOutput
+	_, cmd := m.requestTargets(args[0], targets, args...)
+	m.noteHiddenMarks()
+	return *m, cmd
+}
+
+// renderPalette renders the palette's prompt and, once a word is started,
+// what it can be completed to.
+func (m Model) renderPalette() string {
+	p := m.palette
+	line := p.input.View()
+	matches := p.matches
+	if len(matches) == 0 {
+		value := p.input.Value()
+		if start, completions := m.completions(); start < len(value) {
+			matches = completions
+		}
+	}
+	for i, s := range matches {
+		style := helpStyle
+		if i == p.match && len(p.matches) > 0 {
+			style = matchStyle
+		}
+		line += "  " + style.Render(s)
+	}
+	return statusStyle.Render(ansi.Truncate(line, max(m.width-2, 1), "…"))
+}
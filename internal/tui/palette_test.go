@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+func paletteModel() Model {
+	m := New(Options{Config: ahab.Config{Confirm: []string{"down", "top"}}})
+	m.root = "/"
+	m.state = stateList
+	m.flat = true
+	m.files = []composeFile{
+		{path: "/media.yaml", meta: ahab.ComposeMeta{Services: []string{"plex", "postgres", "redis"}}},
+		{path: "/web.yaml", meta: ahab.ComposeMeta{Services: []string{"nginx"}}},
+	}
+	return m
+}
+
+func TestModel_complete(t *testing.T) {
+	m := paletteModel()
+	m.openPalette()
+
+	tab := func(value string) string {
+		if value != "" {
+			m.palette.input.SetValue(value)
+			m.palette.matches = nil
+		}
+		m.complete()
+		return m.palette.input.Value()
+	}
+	tests := []struct{ value, want string }{
+		{"to", "top "},
+		{"up -d p", "up -d p"},
+		{"config --services r", "config --services redis "},
+		{"ps ngi", "ps ngi"}, // nginx isn't in the selected stack
+		{"xyz", "xyz"},
+	}
+	for _, tt := range tests {
+		if got := tab(tt.value); got != tt.want {
+			t.Errorf("completing %q = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+
+	// Once the matches agree no further, tabs cycle through them.
+	if got := tab("pa"); got != "pause " {
+		t.Fatalf("completing pa = %q", got)
+	}
+	tab("restart p")
+	if got := m.palette.matches; !slices.Equal(got, []string{"plex", "postgres"}) {
+		t.Fatalf("matches = %v", got)
+	}
+	for _, want := range []string{"restart plex", "restart postgres", "restart plex"} {
+		if got := tab(""); got != want {
+			t.Errorf("tab = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestModel_runCommand(t *testing.T) {
+	m := paletteModel()
+	m.expanded["/media.yaml"] = true
+	m.cursor = 1 // media.yaml:plex
+
+	next, _ := m.runCommand("top")
+	m = next.(Model)
+	if m.confirm == nil {
+		t.Fatal("top did not ask for confirmation")
+	}
+	if m.pane != modeOutput {
+		t.Errorf("pane = %v, want output", m.pane)
+	}
+	want := []ahab.Target{{File: "/media.yaml", Services: []string{"plex"}}}
+	if m.confirm.action != "top" || !slices.Equal(m.confirm.args, []string{"top"}) ||
+		len(m.confirm.targets) != 1 || !slices.Equal(m.confirm.targets[0].Services, want[0].Services) {
+		t.Errorf("confirm = %+v, want top on %v", m.confirm, want)
+	}
+
+	// A command naming services runs on the whole stack.
+	m.confirm = nil
+	next, _ = m.runCommand("down redis")
+	m = next.(Model)
+	if m.confirm == nil || len(m.confirm.targets[0].Services) != 0 ||
+		!slices.Equal(m.confirm.args, []string{"down", "redis"}) {
+		t.Errorf("confirm = %+v, want down redis on /media.yaml", m.confirm)
+	}
+}
+
+func TestModel_runCommand_unrunnable(t *testing.T) {
+	m := paletteModel()
+
+	next, cmd := m.runCommand("logs -f")
+	m = next.(Model)
+	if cmd != nil || m.confirm != nil || m.pane == modeOutput {
+		t.Error("logs -f ran from the palette")
+	}
+	if !strings.Contains(m.statusMsg, "L log-viewer") {
+		t.Errorf("statusMsg = %q, want a hint for the log viewer", m.statusMsg)
+	}
+
+	// Commands that stream or want a terminal by default are told not to.
+	m.config.Confirm = []string{"stats", "exec", "up"}
+	for line, want := range map[string][]string{
+		"stats":              {"stats", "--no-stream"},
+		"stats --no-stream":  {"stats", "--no-stream"},
+		"exec plex ls /data": {"exec", "-T", "plex", "ls", "/data"},
+		"up":                 {"up", "-d"},
+		"up --wait":          {"up", "--wait"},
+		"up --detach nginx":  {"up", "--detach", "nginx"},
+	} {
+		m.confirm = nil
+		next, _ = m.runCommand(line)
+		if c := next.(Model).confirm; c == nil || !slices.Equal(c.args, want) {
+			t.Errorf("running %q: confirm = %+v, want args %v", line, c, want)
+		}
+	}
+
+	// On a service row, exec still needs the service named.
+	m.expanded["/media.yaml"] = true
+	m.cursor = 1 // media.yaml:plex
+	m.confirm = nil
+	next, _ = m.runCommand("exec ls")
+	if m = next.(Model); m.confirm != nil {
+		t.Errorf("exec ls ran as %v", m.confirm.args)
+	}
+	next, _ = m.runCommand("exec plex ls")
+	if c := next.(Model).confirm; c == nil || len(c.targets[0].Services) != 0 {
+		t.Errorf("exec plex ls: confirm = %+v, want it on the whole stack", c)
+	}
+}
+
+func TestModel_updatePalette(t *testing.T) {
+	m := paletteModel()
+	next, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{':'}})
+	m = next.(Model)
+	if m.palette == nil {
+		t.Fatal(": did not open the palette")
+	}
+	next, _ = m.updatePalette(tea.KeyMsg{Type: tea.KeyEsc})
+	if next.(Model).palette != nil {
+		t.Error("esc did not close the palette")
+	}
+}
@@ -0,0 +1,238 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// resolvedMsg carries the output of docker compose config for a file.
+type resolvedMsg struct {
+	path string
+	text string
+	err  error
+}
+
+func (m *Model) loadPreview() {
+	f, ok := m.selected()
+	if m.preview != "" || !ok {
+		return
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		m.preview = fmt.Sprintf("error reading file: %v", err)
+		return
+	}
+	m.preview = string(data)
+}
+
+// fetchResolved starts resolving the selected file when the preview shows
+// the resolved config and it isn't loaded or loading yet.
+func (m *Model) fetchResolved() tea.Cmd {
+	if m.pane != modePreview || !m.previewResolved {
+		return nil
+	}
+	f, ok := m.selected()
+	if !ok || m.resolving == f.path {
+		return nil
+	}
+	if _, ok := m.resolved[f.path]; ok {
+		return nil
+	}
+	m.resolving = f.path
+	path := f.path
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		text, err := ahab.ComposeConfig(ctx, path)
+		return resolvedMsg{path: path, text: text, err: err}
+	}
+}
+
+func (m *Model) setResolved(msg resolvedMsg) {
+	if m.resolving == msg.path {
+		m.resolving = ""
+	}
+	if msg.err != nil {
+		m.resolved[msg.path] = "# docker compose config failed:\n# " + strings.ReplaceAll(msg.err.Error(), "\n", "\n# ")
+		return
+	}
+	m.resolved[msg.path] = msg.text
+}
+
+// toggleResolved switches the preview between the file and its resolved
+// config, which is fetched again each time it is shown.
+func (m *Model) toggleResolved() {
+	m.stopLogStreamer()
+	if m.pane == modePreview {
+		m.previewResolved = !m.previewResolved
+	} else {
+		m.pane = modePreview
+		m.previewResolved = true
+	}
+	if f, ok := m.selected(); ok && m.previewResolved {
+		delete(m.resolved, f.path)
+	}
+	m.previewScroll = 0
+	m.loadPreview()
+}
+
+// previewText returns the text shown in the preview and whether it is ready.
+func (m Model) previewText() (string, bool) {
+	if !m.previewResolved {
+		return m.preview, m.preview != ""
+	}
+	f, ok := m.selected()
+	if !ok {
+		return "", false
+	}
+	text, ok := m.resolved[f.path]
+	return text, ok
+}
+
+// scrollPreview scrolls the preview by delta lines, stopping once the last
+// line is at the bottom of the pane.
+func (m *Model) scrollPreview(delta int) {
+	text, _ := m.previewText()
+	lines := strings.Count(strings.TrimRight(text, "\n"), "\n") + 1
+	last := max(lines-previewLines(m.contentHeight()), 0)
+	m.previewScroll = min(max(m.previewScroll+delta, 0), last)
+}
+
+// previewLines is how many lines of the file a preview of this height shows,
+// below its title.
+func previewLines(height int) int {
+	return max(height-3, 1)
+}
+
+func (m Model) renderPreview(height int) string {
+	var b strings.Builder
+	title := "preview"
+	if m.previewResolved {
+		title = "preview (resolved)"
+	}
+	text, ok := m.previewText()
+	if !ok {
+		b.WriteString(titleStyle.Render(title) + "\n\n")
+		b.WriteString(dimStyle.Render("  loading...") + "\n")
+		return b.String()
+	}
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	maxLines := previewLines(height)
+	start := min(m.previewScroll, max(len(lines)-maxLines, 0))
+	end := min(start+maxLines, len(lines))
+	b.WriteString(titleStyle.Render(title) + dimStyle.Render(fmt.Sprintf("%d-%d/%d", start+1, end, len(lines))) + "\n\n")
+
+	digits := len(fmt.Sprint(len(lines)))
+	width := max(m.width-m.width/2-2, 10)
+	for i, line := range lines[start:end] {
+		num := lineNumberStyle.Render(fmt.Sprintf("%*d ", digits, start+i+1))
+		b.WriteString(" " + ansi.Truncate(num+highlightYAML(line), width, "…") + "\n")
+	}
+	return b.String()
+}
+
+var (
+	yamlKey    = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^\s#'"][^:#]*?):(\s|$)`)
+	yamlNumber = regexp.MustCompile(`^[-+]?(\d[\d_]*(\.\d*)?([eE][-+]?\d+)?|0x[0-9a-fA-F]+|\.inf|\.nan)$`)
+)
+
+// highlightYAML colors a line of YAML: comments, keys, list markers, and
+// quoted, numeric, boolean and null values.
+func highlightYAML(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	indent := line[:len(line)-len(trimmed)]
+	if strings.HasPrefix(trimmed, "#") {
+		return indent + yamlCommentStyle.Render(trimmed)
+	}
+	if trimmed == "---" || trimmed == "..." {
+		return yamlPunctStyle.Render(line)
+	}
+	code, comment := splitYAMLComment(trimmed)
+
+	var b strings.Builder
+	b.WriteString(indent)
+	for strings.HasPrefix(code, "- ") || code == "-" {
+		b.WriteString(yamlPunctStyle.Render("-"))
+		code = strings.TrimPrefix(code, "-")
+		rest := strings.TrimLeft(code, " ")
+		b.WriteString(code[:len(code)-len(rest)])
+		code = rest
+	}
+	if loc := yamlKey.FindStringSubmatchIndex(code); loc != nil {
+		b.WriteString(yamlKeyStyle.Render(code[:loc[3]]) + yamlPunctStyle.Render(":"))
+		code = code[loc[3]+1:]
+		rest := strings.TrimLeft(code, " ")
+		b.WriteString(code[:len(code)-len(rest)])
+		code = rest
+	}
+	b.WriteString(highlightScalar(code))
+	if comment != "" {
+		b.WriteString(yamlCommentStyle.Render(comment))
+	}
+	return b.String()
+}
+
+// highlightScalar colors a YAML value by its type.
+func highlightScalar(v string) string {
+	switch scalarKind(strings.TrimRight(v, " ")) {
+	case "string":
+		return yamlStringStyle.Render(v)
+	case "anchor":
+		return yamlAnchorStyle.Render(v)
+	case "block":
+		return yamlPunctStyle.Render(v)
+	case "literal":
+		return yamlLiteralStyle.Render(v)
+	}
+	return v
+}
+
+// scalarKind classifies a YAML value as a quoted "string", an "anchor",
+// alias or tag, a "block" scalar indicator, a "literal" number, boolean or
+// null, or "" for a plain value.
+func scalarKind(v string) string {
+	switch {
+	case v == "":
+		return ""
+	case strings.HasPrefix(v, `"`) || strings.HasPrefix(v, "'"):
+		return "string"
+	case strings.HasPrefix(v, "&") || strings.HasPrefix(v, "*") || strings.HasPrefix(v, "!"):
+		return "anchor"
+	case v == "|" || v == ">" || strings.HasPrefix(v, "|-") || strings.HasPrefix(v, ">-"):
+		return "block"
+	case yamlNumber.MatchString(v):
+		return "literal"
+	}
+	switch strings.ToLower(v) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return "literal"
+	}
+	return ""
+}
+
+// splitYAMLComment splits a trailing comment, one starting with " #"
+// outside of quotes, from a line.
+func splitYAMLComment(line string) (code, comment string) {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" [{,", line[i-1]) >= 0):
+			quote = c
+		case c == '#' && i > 0 && line[i-1] == ' ':
+			return line[:i], line[i:]
+		}
+	}
+	return line, ""
+}
@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func Test_highlightYAML(t *testing.T) {
+	lines := []string{
+		"services:",
+		"  web:",
+		`    image: "nginx:1.27" # pinned`,
+		"    ports:",
+		"      - 8080:80",
+		"    restart: unless-stopped",
+		"# a comment",
+		"    command: echo '#not a comment'",
+	}
+	for _, line := range lines {
+		// Highlighting only adds colors; the text must be unchanged.
+		if got := ansi.Strip(highlightYAML(line)); got != line {
+			t.Errorf("highlightYAML(%q) text = %q", line, got)
+		}
+	}
+}
+
+func Test_splitYAMLComment(t *testing.T) {
+	tests := []struct {
+		line, code, comment string
+	}{
+		{"image: nginx # latest", "image: nginx ", "# latest"},
+		{`command: "echo #1"`, `command: "echo #1"`, ""},
+		{"url: http://host/#anchor", "url: http://host/#anchor", ""},
+		{"name: it's # ok", "name: it's ", "# ok"},
+	}
+	for _, tt := range tests {
+		code, comment := splitYAMLComment(tt.line)
+		if code != tt.code || comment != tt.comment {
+			t.Errorf("splitYAMLComment(%q) = %q, %q, want %q, %q", tt.line, code, comment, tt.code, tt.comment)
+		}
+	}
+}
+
+func Test_scalarKind(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"8080", "literal"},
+		{"1.5e3", "literal"},
+		{"true", "literal"},
+		{"null", "literal"},
+		{`"quoted"`, "string"},
+		{"*default", "anchor"},
+		{"|-", "block"},
+		{"nginx", ""},
+		{"1.27-alpine", ""},
+	}
+	for _, tt := range tests {
+		if got := scalarKind(tt.value); got != tt.want {
+			t.Errorf("scalarKind(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestModel_scrollPreview(t *testing.T) {
+	m := treeModel()
+	m.state = stateList
+	m.width, m.height = 80, 20
+	m.pane = modePreview
+	var lines []string
+	for i := range 50 {
+		lines = append(lines, fmt.Sprintf("line %d", i+1))
+	}
+	m.preview = strings.Join(lines, "\n") + "\n"
+
+	for _, toast := range []string{"", "saved"} {
+		m.toast = toast
+		m.previewScroll = 0
+		m.scrollPreview(1000)
+		view := ansi.Strip(m.View())
+		if !strings.Contains(view, "line 50") {
+			t.Errorf("toast %q: scrolled to the end, the last line isn't shown:\n%s", toast, view)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+type serviceStatusMsg struct {
+	path     string
+	statuses map[string]string
+	err      error
+}
+
+// fetchServiceStatuses checks the status of every service in a compose file.
+func fetchServiceStatuses(path string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		statuses, err := ahab.GetServiceStatuses(ctx, path)
+		return serviceStatusMsg{path: path, statuses: statuses, err: err}
+	}
+}
+
+// refreshExpanded re-checks service statuses for every expanded stack.
+func (m Model) refreshExpanded() tea.Cmd {
+	var cmds []tea.Cmd
+	for path := range m.expanded {
+		cmds = append(cmds, fetchServiceStatuses(path))
+	}
+	return tea.Batch(cmds...)
+}
+
+// serviceNames returns a file's services: those declared in the file plus
+// any that only exist as containers.
+func (m Model) serviceNames(f composeFile) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, s := range f.meta.Services {
+		seen[s] = true
+		names = append(names, s)
+	}
+	var extra []string
+	for s := range m.services[f.path] {
+		if !seen[s] {
+			extra = append(extra, s)
+		}
+	}
+	sort.Strings(extra)
+	return append(names, extra...)
+}
+
+// serviceStatus returns the status of one service; a declared service with no
+// containers is stopped.
+func (m Model) serviceStatus(path, service string) string {
+	statuses, ok := m.services[path]
+	if !ok {
+		return "unknown"
+	}
+	if s, ok := statuses[service]; ok {
+		return s
+	}
+	return "stopped"
+}
+
+// toggleExpand shows or hides the services of the stack under the cursor.
+func (m *Model) toggleExpand(expand bool) tea.Cmd {
+	row, ok := m.currentRow()
+	if !ok || row.kind == rowDir {
+		return nil
+	}
+	path := m.files[row.file].path
+	if !expand {
+		delete(m.expanded, path)
+		if row.kind == rowService {
+			m.cursorToFile(path)
+		}
+		return nil
+	}
+	m.expanded[path] = true
+	return fetchServiceStatuses(path)
+}
+
+// cursorToFile moves the cursor onto the file row for path.
+func (m *Model) cursorToFile(path string) {
+	for i, r := range m.rows() {
+		if r.kind == rowFile && m.files[r.file].path == path {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+func (m Model) renderServiceRow(row listRow) string {
+	f := m.files[row.file]
+	status := m.serviceStatus(f.path, row.service)
+	return fmt.Sprintf(" %s%s %s %s", strings.Repeat("  ", row.depth), statusIndicator(status),
+		row.service, dimStyle.Render(status))
+}
@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// shellProbeMsg carries the shell to open in a service, or why none was
+// found.
+type shellProbeMsg struct {
+	path    string
+	service string
+	shell   string
+	err     error
+}
+
+type shellDoneMsg struct {
+	path    string
+	service string
+	err     error
+}
+
+// shellTarget returns the service to open a shell in: the service under the
+// cursor, or the selected stack's service if it only has one.
+func (m Model) shellTarget() (composeFile, string, bool) {
+	row, ok := m.currentRow()
+	if !ok || row.kind == rowDir {
+		return composeFile{}, "", false
+	}
+	f := m.files[row.file]
+	if row.kind == rowService {
+		return f, row.service, true
+	}
+	if names := m.serviceNames(f); len(names) == 1 {
+		return f, names[0], true
+	}
+	return f, "", false
+}
+
+// openShell finds the shell to run in the selected service: the configured
+// one, or the first of sh, bash and /bin/sh that works.
+func (m *Model) openShell() tea.Cmd {
+	f, service, ok := m.shellTarget()
+	if !ok {
+		m.statusMsg = "select a service to open a shell in"
+		return nil
+	}
+	shell := m.config.ShellFor(m.root, f.path, service)
+	m.statusMsg = "opening a shell in " + service + "..."
+	return func() tea.Msg {
+		if shell != "" {
+			return shellProbeMsg{path: f.path, service: service, shell: shell}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		shell, err := ahab.DetectShell(ctx, f.path, service)
+		return shellProbeMsg{path: f.path, service: service, shell: shell, err: err}
+	}
+}
+
+// execShell suspends the TUI and runs docker compose exec with the shell.
+func (m *Model) execShell(msg shellProbeMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.reportError(msg.path, "shell", msg.err)
+	}
+	args := append([]string{"compose", "-f", msg.path, "exec", msg.service}, strings.Fields(msg.shell)...)
+	m.statusMsg = "shell in " + msg.service
+	return tea.ExecProcess(exec.Command("docker", args...), func(err error) tea.Msg {
+		return shellDoneMsg{path: msg.path, service: msg.service, err: err}
+	})
+}
+
+// shellDone refreshes the stack's status after its shell exits. A shell
+// exiting with the status of its last command isn't an error.
+func (m *Model) shellDone(msg shellDoneMsg) tea.Cmd {
+	var exitErr *exec.ExitError
+	if msg.err != nil && !errors.As(msg.err, &exitErr) {
+		return m.reportError(msg.path, "shell", msg.err)
+	}
+	m.statusMsg = "left the shell in " + msg.service
+	return tea.Batch(m.startRefresh(), fetchServiceStatuses(msg.path))
+}
@@ -0,0 +1,32 @@
+package tui
+
+import (
+	"testing"
+
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+func TestModel_shellTarget(t *testing.T) {
+	m := New(Options{})
+	m.root = "/"
+	m.flat = true
+	m.files = []composeFile{
+		{path: "/a.yaml", meta: ahab.ComposeMeta{Services: []string{"web"}}},
+		{path: "/b.yaml", meta: ahab.ComposeMeta{Services: []string{"app", "db"}}},
+	}
+
+	if _, service, ok := m.shellTarget(); !ok || service != "web" {
+		t.Errorf("shellTarget() on single-service stack = %q, %v, want web", service, ok)
+	}
+
+	m.cursor = 1
+	if _, service, ok := m.shellTarget(); ok {
+		t.Errorf("shellTarget() on multi-service stack = %q, want none", service)
+	}
+
+	m.expanded["/b.yaml"] = true
+	m.cursor = 3 // a.yaml, b.yaml, b.yaml:app, b.yaml:db
+	if f, service, ok := m.shellTarget(); !ok || f.path != "/b.yaml" || service != "db" {
+		t.Errorf("shellTarget() on service row = %q %q, %v, want /b.yaml db", f.path, service, ok)
+	}
+}
@@ -0,0 +1,231 @@
+package tui
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// sortModes are the stack list's sort orders, in the order the sort key
+// cycles through them. "path" is the order the files were found in.
+var sortModes = []string{"path", "name", "status", "action", "memory", "updates"}
+
+type uiStateLoadedMsg struct {
+	state ahab.UIState
+	err   error
+}
+
+type uiStateErrMsg struct{ err error }
+
+// loadUIState reads what the previous session left in the state directory.
+func loadUIState() tea.Cmd {
+	return func() tea.Msg {
+		path, err := ahab.UIStatePath()
+		if err != nil {
+			return uiStateLoadedMsg{err: err}
+		}
+		s, err := ahab.LoadUIState(path)
+		return uiStateLoadedMsg{state: s, err: err}
+	}
+}
+
+// saveUIState writes the state to remember for the next session.
+func (m Model) saveUIState() tea.Cmd {
+	s := ahab.UIState{Sort: m.sortMode}
+	return func() tea.Msg {
+		path, err := ahab.UIStatePath()
+		if err == nil {
+			err = ahab.SaveUIState(path, s)
+		}
+		if err != nil {
+			return uiStateErrMsg{err}
+		}
+		return nil
+	}
+}
+
+// setUIState restores the previous session's sort. An unknown sort, say from
+// a newer version, is ignored.
+func (m *Model) setUIState(msg uiStateLoadedMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.reportError("", "state", msg.err)
+	}
+	if slices.Contains(sortModes, msg.state.Sort) {
+		m.setSort(msg.state.Sort)
+	}
+	return nil
+}
+
+// cycleSort switches to the next sort order and remembers it.
+func (m *Model) cycleSort() tea.Cmd {
+	i := slices.Index(sortModes, m.sortMode)
+	m.setSort(sortModes[(i+1)%len(sortModes)])
+	return m.saveUIState()
+}
+
+// setSort changes the sort order, keeping the cursor on the same row.
+func (m *Model) setSort(mode string) {
+	key := m.cursorKey()
+	m.sortMode = mode
+	m.resort()
+	if !m.restoreCursor(key) {
+		m.clampCursor()
+		m.selectionChanged()
+	}
+}
+
+// sortLive reports whether the sort order depends on data that changes while
+// the list is open, so rows can move under the cursor.
+func (m Model) sortLive() bool {
+	switch m.sortMode {
+	case "status", "action", "memory", "updates":
+		return true
+	}
+	return false
+}
+
+// resort recomputes the list's order, rather than every time the list is
+// rendered. It runs whenever the files, the sort, the view or the data the
+// sort uses change.
+func (m *Model) resort() {
+	m.order = m.sortOrder()
+}
+
+// sortOrder returns the index of every file, in list order.
+func (m Model) sortOrder() []int {
+	idx := make([]int, len(m.files))
+	for i := range idx {
+		idx[i] = i
+	}
+	m.sortFiles(idx)
+	return idx
+}
+
+// sortFiles orders file indexes by the sort mode, keeping path order for
+// ties. In tree view the sort applies to each directory's entries, with a
+// directory placed by the first file beneath it, so directories stay together.
+func (m Model) sortFiles(idx []int) {
+	compare := m.sortCompare()
+	if compare == nil {
+		return
+	}
+	if m.flat {
+		slices.SortStableFunc(idx, func(a, b int) int {
+			return compare(m.files[a], m.files[b])
+		})
+		return
+	}
+
+	// first is the file each directory is placed by.
+	first := make(map[string]composeFile)
+	for _, i := range idx {
+		f := m.files[i]
+		parts := dirParts(m.relDir(f.path))
+		for d := range parts {
+			prefix := strings.Join(parts[:d+1], "/")
+			if cur, ok := first[prefix]; !ok || compare(f, cur) < 0 {
+				first[prefix] = f
+			}
+		}
+	}
+	slices.SortStableFunc(idx, func(a, b int) int {
+		fa, fb := m.files[a], m.files[b]
+		da, db := m.relDir(fa.path), m.relDir(fb.path)
+		pa, pb := dirParts(da), dirParts(db)
+		// Compare the entries the two files are under where their paths part.
+		d := 0
+		for d < len(pa) && d < len(pb) && pa[d] == pb[d] {
+			d++
+		}
+		if d < len(pa) {
+			fa = first[strings.Join(pa[:d+1], "/")]
+		}
+		if d < len(pb) {
+			fb = first[strings.Join(pb[:d+1], "/")]
+		}
+		return cmp.Or(compare(fa, fb), compareDirs(da, db))
+	})
+}
+
+// dirParts splits a relative directory into its components.
+func dirParts(dir string) []string {
+	if dir == "" {
+		return nil
+	}
+	return strings.Split(dir, "/")
+}
+
+// sortCompare returns the comparison for the sort mode, or nil to keep path
+// order.
+func (m Model) sortCompare() func(a, b composeFile) int {
+	switch m.sortMode {
+	case "name":
+		return func(a, b composeFile) int {
+			return cmp.Or(cmp.Compare(ahab.StackName(m.root, a.path), ahab.StackName(m.root, b.path)), cmp.Compare(a.path, b.path))
+		}
+	case "status":
+		return func(a, b composeFile) int {
+			return cmp.Compare(m.statusRank(a), m.statusRank(b))
+		}
+	case "action":
+		last := m.lastActions()
+		return func(a, b composeFile) int {
+			// Most recent first; stacks never acted on go last.
+			return last[b.path].Compare(last[a.path])
+		}
+	case "memory":
+		return func(a, b composeFile) int {
+			return cmp.Compare(m.memUsage(b.path), m.memUsage(a.path))
+		}
+	case "updates":
+		return func(a, b composeFile) int {
+			return cmp.Compare(len(m.updates[b.path]), len(m.updates[a.path]))
+		}
+	}
+	return nil
+}
+
+// compareDirs orders directories component by component, so a directory's
+// subdirectories sort right after it.
+func compareDirs(a, b string) int {
+	return slices.Compare(dirParts(a), dirParts(b))
+}
+
+// statusRank puts stacks needing attention first: failed or unhealthy, then
+// partially running, stopped, unknown and finally running.
+func (m Model) statusRank(f composeFile) int {
+	switch {
+	case m.stackErrs[f.path] != "" || f.unhealthy > 0:
+		return 0
+	case f.status == "partial":
+		return 1
+	case f.status == "stopped":
+		return 2
+	case f.status == "running":
+		return 4
+	default:
+		return 3
+	}
+}
+
+// lastActions returns when an action on each stack last finished.
+func (m Model) lastActions() map[string]time.Time {
+	last := make(map[string]time.Time)
+	for _, e := range m.history {
+		if end := e.Start.Add(e.Duration); end.After(last[e.File]) {
+			last[e.File] = end
+		}
+	}
+	return last
+}
+
+func (m Model) memUsage(path string) uint64 {
+	if s := m.stats[path]; s != nil {
+		return s.mem
+	}
+	return 0
+}
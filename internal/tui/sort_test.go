@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+func TestModel_sortFiles(t *testing.T) {
+	m := treeModel()
+	m.flat = true
+	m.files = append(m.files, composeFile{path: "/docker/apps/arr/compose.yaml", status: "partial"})
+	m.stackErrs["/docker/traefik/compose.yaml"] = "exit status 1"
+	m.stats = map[string]*stackStats{
+		"/docker/apps/plex/compose.yaml": {mem: 2 << 30},
+		"/docker/root.yaml":              {mem: 1 << 30},
+	}
+	m.updates["/docker/root.yaml"] = []string{"redis:7"}
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	m.history = []ahab.HistoryEntry{
+		{File: "/docker/apps/grafana/compose.yaml", Start: start},
+		{File: "/docker/apps/arr/compose.yaml", Start: start.Add(time.Minute)},
+	}
+
+	names := func() []string {
+		var out []string
+		for _, i := range m.visible() {
+			out = append(out, ahab.StackName(m.root, m.files[i].path))
+		}
+		return out
+	}
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{"path", []string{"apps/grafana", "apps/plex", "traefik", "root.yaml", "apps/arr"}},
+		{"name", []string{"apps/arr", "apps/grafana", "apps/plex", "root.yaml", "traefik"}},
+		{"status", []string{"traefik", "apps/arr", "apps/plex", "apps/grafana", "root.yaml"}},
+		{"action", []string{"apps/arr", "apps/grafana", "apps/plex", "traefik", "root.yaml"}},
+		{"memory", []string{"apps/plex", "root.yaml", "apps/grafana", "traefik", "apps/arr"}},
+		{"updates", []string{"root.yaml", "apps/grafana", "apps/plex", "traefik", "apps/arr"}},
+	}
+	for _, tt := range tests {
+		m.sortMode = tt.mode
+		if got := names(); !slices.Equal(got, tt.want) {
+			t.Errorf("sort %s = %v, want %v", tt.mode, got, tt.want)
+		}
+	}
+
+	// In tree view directories move with the first file beneath them.
+	m.flat = false
+	m.sortMode = "name"
+	if got, want := names(), []string{"apps/arr", "apps/grafana", "apps/plex", "root.yaml", "traefik"}; !slices.Equal(got, want) {
+		t.Errorf("tree sort name = %v, want %v", got, want)
+	}
+	m.sortMode = "memory"
+	if got, want := names(), []string{"apps/plex", "apps/arr", "apps/grafana", "root.yaml", "traefik"}; !slices.Equal(got, want) {
+		t.Errorf("tree sort memory = %v, want %v", got, want)
+	}
+}
+
+func TestModel_resort(t *testing.T) {
+	m := treeModel()
+	m.state = stateList
+	m.flat = true
+	m.setSort("updates")
+	first := func() string {
+		return m.files[m.visible()[0]].path
+	}
+
+	// The order is kept until the data it sorts by changes, not recomputed
+	// on every render or tick.
+	m.updates["/docker/traefik/compose.yaml"] = []string{"traefik:v3"}
+	next, _ := m.Update(statsTickMsg{})
+	m = next.(Model)
+	if got := first(); got != "/docker/apps/grafana/compose.yaml" {
+		t.Errorf("first row before an update = %s, want the cached order", got)
+	}
+
+	next, _ = m.Update(updatesMsg{path: "/docker/root.yaml", images: []string{"redis:7"}})
+	m = next.(Model)
+	if got := first(); got != "/docker/traefik/compose.yaml" {
+		t.Errorf("first row after an update = %s, want traefik", got)
+	}
+}
+
+func TestModel_cycleSort(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	m := treeModel()
+	m.state = stateList
+	m.flat = true
+	m.cursor = 2 // traefik
+
+	cmd := m.cycleSort()
+	if m.sortMode != "name" {
+		t.Fatalf("sortMode = %q, want name", m.sortMode)
+	}
+	if row, _ := m.currentRow(); m.files[row.file].path != "/docker/traefik/compose.yaml" {
+		t.Errorf("cursor moved to %s", m.files[row.file].path)
+	}
+	if msg := cmd(); msg != nil {
+		t.Fatalf("saving the sort: %v", msg)
+	}
+
+	// The next session starts with the saved sort.
+	m = treeModel()
+	m.setUIState(loadUIState()().(uiStateLoadedMsg))
+	if m.sortMode != "name" {
+		t.Errorf("restored sortMode = %q, want name", m.sortMode)
+	}
+
+	for range sortModes {
+		m.cycleSort()
+	}
+	if m.sortMode != "name" {
+		t.Errorf("cycling through every sort ends on %q, want name", m.sortMode)
+	}
+}
@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// statsInterval is the delay between docker stats samples.
+const statsInterval = 5 * time.Second
+
+type statsTickMsg struct{}
+type statsMsg struct {
+	stats []ahab.ContainerStats
+	err   error
+}
+
+// stackStats aggregates the container samples belonging to one compose file.
+type stackStats struct {
+	containers []ahab.ContainerStats
+	cpu        float64
+	mem        uint64
+	net        [2]uint64
+	block      [2]uint64
+}
+
+func sampleStats() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		stats, err := ahab.GetComposeStats(ctx)
+		return statsMsg{stats: stats, err: err}
+	}
+}
+
+func statsTickCmd() tea.Cmd {
+	return tea.Tick(statsInterval, func(time.Time) tea.Msg {
+		return statsTickMsg{}
+	})
+}
+
+// setStats groups a stats sample by compose file.
+func (m *Model) setStats(stats []ahab.ContainerStats) {
+	m.stats = make(map[string]*stackStats)
+	for _, cs := range stats {
+		idx := m.fileForConfigFiles(cs.ConfigFiles)
+		if idx < 0 {
+			continue
+		}
+		path := m.files[idx].path
+		st := m.stats[path]
+		if st == nil {
+			st = &stackStats{}
+			m.stats[path] = st
+		}
+		st.containers = append(st.containers, cs)
+		st.cpu += cs.CPUPercent
+		st.mem += cs.MemUsage
+		st.net[0] += cs.NetRx
+		st.net[1] += cs.NetTx
+		st.block[0] += cs.BlockRead
+		st.block[1] += cs.BlockWrite
+	}
+	m.resort()
+}
+
+func (m Model) renderStats(height int) string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("resources") + "\n\n")
+	f, ok := m.selected()
+	if !ok {
+		b.WriteString(dimStyle.Render("  no file selected") + "\n")
+		return b.String()
+	}
+	st := m.stats[f.path]
+	if st == nil {
+		b.WriteString(dimStyle.Render("  no running containers") + "\n")
+		return b.String()
+	}
+
+	rows := 0
+	for _, cs := range st.containers {
+		if rows >= height-6 {
+			break
+		}
+		b.WriteString(normalStyle.Render(cs.Name) + "\n")
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  cpu %5.1f%%  mem %s / %s",
+			cs.CPUPercent, ahab.FormatBytes(cs.MemUsage), ahab.FormatBytes(cs.MemLimit))) + "\n")
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  net %s / %s  block %s / %s",
+			ahab.FormatBytes(cs.NetRx), ahab.FormatBytes(cs.NetTx),
+			ahab.FormatBytes(cs.BlockRead), ahab.FormatBytes(cs.BlockWrite))) + "\n")
+		rows += 3
+	}
+	b.WriteString("\n" + normalStyle.Render(fmt.Sprintf("Total: cpu %.1f%%  mem %s  net %s / %s  block %s / %s",
+		st.cpu, ahab.FormatBytes(st.mem),
+		ahab.FormatBytes(st.net[0]), ahab.FormatBytes(st.net[1]),
+		ahab.FormatBytes(st.block[0]), ahab.FormatBytes(st.block[1]))) + "\n")
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// statusWorkers bounds how many docker compose ps commands run at once.
+const statusWorkers = 4
+
+type refreshStatusMsg struct {
+	gen     int
+	status  fileStatusMsg
+	results <-chan fileStatusMsg
+}
+type refreshDoneMsg struct{ gen int }
+type refreshTickMsg struct{}
+
+// startRefresh cancels any in-flight refresh and checks every file's status
+// on a bounded worker pool, reporting each result as soon as it is known.
+func (m *Model) startRefresh() tea.Cmd {
+	m.cancelRefresh()
+	if len(m.files) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.refreshCancel = cancel
+	m.refreshGen++
+
+	queue := make([]string, len(m.files))
+	for i, f := range m.files {
+		queue[i] = f.path
+	}
+	paths := make(chan string)
+	results := make(chan fileStatusMsg, len(m.files))
+	var wg sync.WaitGroup
+	for i := 0; i < statusWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				state := ahab.GetComposeState(ctx, path)
+				if ctx.Err() != nil {
+					return
+				}
+				results <- fileStatusMsg{path: path, status: state.Status, unhealthy: state.Unhealthy}
+			}
+		}()
+	}
+	go func() {
+		defer close(results)
+		defer wg.Wait()
+		defer close(paths)
+		for _, path := range queue {
+			select {
+			case paths <- path:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return waitForStatus(m.refreshGen, results)
+}
+
+// waitForStatus blocks until the next status result of a refresh arrives.
+func waitForStatus(gen int, results <-chan fileStatusMsg) tea.Cmd {
+	return func() tea.Msg {
+		res, ok := <-results
+		if !ok {
+			return refreshDoneMsg{gen}
+		}
+		return refreshStatusMsg{gen: gen, status: res, results: results}
+	}
+}
+
+func (m *Model) cancelRefresh() {
+	if m.refreshCancel != nil {
+		m.refreshCancel()
+		m.refreshCancel = nil
+	}
+}
+
+func (m *Model) setFileStatus(msg fileStatusMsg) {
+	for i := range m.files {
+		if m.files[i].path == msg.path {
+			m.files[i].status = msg.status
+			m.files[i].unhealthy = msg.unhealthy
+		}
+	}
+	m.resort()
+}
+
+func (m Model) refreshTickCmd() tea.Cmd {
+	if m.refreshInterval <= 0 {
+		return nil
+	}
+	return tea.Tick(m.refreshInterval, func(time.Time) tea.Msg {
+		return refreshTickMsg{}
+	})
+}
@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeDocker puts a docker script running body first in PATH.
+func fakeDocker(t *testing.T, body string) {
+	t.Helper()
+	dir := t.TempDir()
+	script := "#!/bin/sh\n" + body + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestModel_startRefresh(t *testing.T) {
+	fakeDocker(t, `echo '{"Service":"web","State":"running"}'`)
+	m := treeModel()
+	m.state = stateList
+
+	msg := m.startRefresh()()
+	for {
+		r, ok := msg.(refreshStatusMsg)
+		if !ok {
+			break
+		}
+		next, cmd := m.Update(r)
+		m = next.(Model)
+		msg = cmd()
+	}
+	if done, ok := msg.(refreshDoneMsg); !ok || done.gen != m.refreshGen {
+		t.Fatalf("refresh ended with %#v", msg)
+	}
+	for _, f := range m.files {
+		if f.status != "running" {
+			t.Errorf("%s status = %q, want running", f.path, f.status)
+		}
+	}
+
+	// Results of an earlier refresh are ignored.
+	stale := refreshStatusMsg{gen: m.refreshGen - 1, status: fileStatusMsg{path: m.files[0].path, status: "stopped"}}
+	next, cmd := m.Update(stale)
+	m = next.(Model)
+	if m.files[0].status != "running" || cmd != nil {
+		t.Errorf("stale result applied: status %q, cmd %v", m.files[0].status, cmd)
+	}
+}
+
+func TestModel_cancelRefresh(t *testing.T) {
+	fakeDocker(t, "exec sleep 30")
+	m := treeModel()
+	m.state = stateList
+
+	cmd := m.startRefresh()
+	m.cancelRefresh()
+
+	done := make(chan any, 1)
+	go func() { done <- cmd() }()
+	select {
+	case msg := <-done:
+		if _, ok := msg.(refreshDoneMsg); !ok {
+			t.Errorf("cancelled refresh reported %#v, want refreshDoneMsg", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelRefresh did not stop the workers")
+	}
+}
@@ -3,38 +3,96 @@ package tui
 import "github.com/charmbracelet/lipgloss"
 
 var (
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("212")).
-			Padding(0, 1)
-
-	selectedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("212")).
-			Background(lipgloss.Color("236")).
-			Padding(0, 1)
-
-	normalStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252")).
-			Padding(0, 1)
-
-	dimStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240")).
-			Padding(0, 1)
-
-	statusStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240")).
-			Padding(0, 1)
-
-	errorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")).
-			Padding(0, 1)
-
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
-
-	logStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240")).
-			BorderStyle(lipgloss.NormalBorder()).
-			BorderTop(true).
-			Padding(0, 1)
+	titleStyle     lipgloss.Style
+	selectedStyle  lipgloss.Style
+	normalStyle    lipgloss.Style
+	dimStyle       lipgloss.Style
+	statusStyle    lipgloss.Style
+	errorStyle     lipgloss.Style
+	errorMarkStyle lipgloss.Style
+	toastStyle     lipgloss.Style
+	jobStyle       lipgloss.Style
+	matchStyle     lipgloss.Style
+	helpStyle      lipgloss.Style
+	spinnerStyle   lipgloss.Style
+
+	successStyle lipgloss.Style
+	warningStyle lipgloss.Style
+	failureStyle lipgloss.Style
+	unknownStyle lipgloss.Style
+	infoStyle    lipgloss.Style
+
+	helpOverlayStyle    lipgloss.Style
+	confirmOverlayStyle lipgloss.Style
+	errorsOverlayStyle  lipgloss.Style
+
+	logStyle       lipgloss.Style
+	logPrefixStyle lipgloss.Style
+	logErrorStyle  lipgloss.Style
+	logWarnStyle   lipgloss.Style
+	logInfoStyle   lipgloss.Style
+	logDebugStyle  lipgloss.Style
+
+	lineNumberStyle  lipgloss.Style
+	yamlKeyStyle     lipgloss.Style
+	yamlStringStyle  lipgloss.Style
+	yamlLiteralStyle lipgloss.Style
+	yamlAnchorStyle  lipgloss.Style
+	yamlCommentStyle lipgloss.Style
+	yamlPunctStyle   lipgloss.Style
 )
+
+func init() {
+	applyTheme(themes["dark"])
+}
+
+// applyTheme sets every style's colors from a theme.
+func applyTheme(t theme) {
+	color := func(slot string) lipgloss.Color { return lipgloss.Color(t[slot]) }
+	fg := func(slot string) lipgloss.Style { return lipgloss.NewStyle().Foreground(color(slot)) }
+	overlay := func(slot string) lipgloss.Style {
+		return lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(color(slot)).
+			Padding(1, 2)
+	}
+
+	titleStyle = fg("accent").Bold(true).Padding(0, 1)
+	// Without colors, the selection is shown in reverse video.
+	selectedStyle = fg("accent").Background(color("selected")).Reverse(t["selected"] == "").Padding(0, 1)
+	normalStyle = fg("text").Padding(0, 1)
+	dimStyle = fg("dim").Padding(0, 1)
+	statusStyle = fg("dim").Padding(0, 1)
+	errorStyle = fg("error").Padding(0, 1)
+	errorMarkStyle = fg("error").Bold(true)
+	toastStyle = fg("toast-fg").Background(color("toast-bg")).Reverse(t["toast-bg"] == "").Padding(0, 1)
+	jobStyle = fg("warning")
+	matchStyle = fg("warning").Bold(true).Underline(true)
+	helpStyle = fg("dim")
+	spinnerStyle = fg("accent")
+
+	successStyle = fg("success")
+	warningStyle = fg("warning")
+	failureStyle = fg("error")
+	unknownStyle = fg("dim")
+	infoStyle = fg("info")
+
+	helpOverlayStyle = overlay("accent")
+	confirmOverlayStyle = overlay("warning")
+	errorsOverlayStyle = overlay("error")
+
+	logStyle = fg("dim").BorderStyle(lipgloss.NormalBorder()).BorderTop(true).Padding(0, 1)
+	logPrefixStyle = fg("dim")
+	logErrorStyle = fg("error").Bold(true)
+	logWarnStyle = fg("warning")
+	logInfoStyle = fg("info")
+	logDebugStyle = fg("muted")
+
+	lineNumberStyle = fg("faint")
+	yamlKeyStyle = fg("info")
+	yamlStringStyle = fg("string")
+	yamlLiteralStyle = fg("warning")
+	yamlAnchorStyle = fg("anchor")
+	yamlCommentStyle = fg("dim").Italic(true)
+	yamlPunctStyle = fg("muted")
+}
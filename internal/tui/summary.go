@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// summaryGap separates the counts in the summary bar.
+const summaryGap = "   "
+
+// summaryCount is a count in the summary bar and the status filter that
+// shows the stacks it counts.
+type summaryCount struct {
+	filter string
+	text   string
+	style  lipgloss.Style
+}
+
+func (m Model) summaryCounts() []summaryCount {
+	byStatus := make(map[string]int)
+	unhealthy, updates := 0, 0
+	for _, f := range m.files {
+		byStatus[f.status]++
+		if f.unhealthy > 0 {
+			unhealthy++
+		}
+		updates += len(m.updates[f.path])
+	}
+	return []summaryCount{
+		{"", fmt.Sprintf("%d stacks", len(m.files)), lipgloss.NewStyle().Bold(true)},
+		{"running", fmt.Sprintf("● %d running", byStatus["running"]), successStyle},
+		{"partial", fmt.Sprintf("◐ %d partial", byStatus["partial"]), warningStyle},
+		{"stopped", fmt.Sprintf("○ %d stopped", byStatus["stopped"]), failureStyle},
+		{"unhealthy", fmt.Sprintf("✚ %d unhealthy", unhealthy), failureStyle},
+		{"updates", fmt.Sprintf("⬆ %d updates", updates), infoStyle},
+	}
+}
+
+// summaryFilterAt returns the filter of the count at column x of the
+// summary bar.
+func (m Model) summaryFilterAt(x int) (string, bool) {
+	pos := 1
+	for _, c := range m.summaryCounts() {
+		w := ansi.StringWidth(c.text)
+		if x >= pos && x < pos+w {
+			return c.filter, true
+		}
+		pos += w + len(summaryGap)
+	}
+	return "", false
+}
+
+// applySummaryFilter shows only the stacks a count counts, or every stack
+// if that filter is already applied.
+func (m *Model) applySummaryFilter(filter string) {
+	if m.statusFilter == filter {
+		filter = ""
+	}
+	m.statusFilter = filter
+	m.clampCursor()
+	m.selectionChanged()
+}
+
+// updateSummary handles keys while a count in the summary bar is selected.
+func (m Model) updateSummary(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.keys.action(msg.String()) == "summary" {
+		m.summaryFocus = false
+		return m, nil
+	}
+	if msg.String() == "ctrl+c" {
+		m.shutdown()
+		return m, tea.Quit
+	}
+	switch m.keys.in("summary", msg.String()) {
+	case "close":
+		m.summaryFocus = false
+	case "left":
+		m.summaryCursor = max(m.summaryCursor-1, 0)
+	case "right":
+		m.summaryCursor = min(m.summaryCursor+1, len(m.summaryCounts())-1)
+	case "select":
+		m.summaryFocus = false
+		m.applySummaryFilter(m.summaryCounts()[m.summaryCursor].filter)
+	}
+	return m, nil
+}
+
+// updateMouse filters the list when a count in the summary bar is clicked.
+func (m Model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft || msg.Y != 0 {
+		return m, nil
+	}
+	if m.state != stateList || m.confirm != nil || m.logView != nil || m.showHelp || m.showErrors || m.showHistory {
+		return m, nil
+	}
+	if filter, ok := m.summaryFilterAt(msg.X); ok {
+		m.summaryFocus = false
+		m.applySummaryFilter(filter)
+	}
+	return m, nil
+}
+
+// renderSummary renders the summary bar: stack counts, outdated images, the
+// last refresh and the Docker host.
+func (m Model) renderSummary() string {
+	var parts []string
+	for i, c := range m.summaryCounts() {
+		text := c.style.Render(c.text)
+		switch {
+		case m.summaryFocus && i == m.summaryCursor:
+			text = lipgloss.NewStyle().Reverse(true).Render(c.text)
+		case c.filter != "" && c.filter == m.statusFilter:
+			text = c.style.Underline(true).Render(c.text)
+		}
+		parts = append(parts, text)
+	}
+	line := " " + strings.Join(parts, summaryGap)
+
+	var info []string
+	switch {
+	case m.refreshCancel != nil:
+		info = append(info, "refreshing...")
+	case !m.lastRefresh.IsZero():
+		info = append(info, "refreshed "+m.lastRefresh.Format("15:04:05"))
+	}
+	if m.dockerHost != "" {
+		info = append(info, m.dockerHost)
+	}
+	if len(info) > 0 {
+		line += summaryGap + helpStyle.Render(strings.Join(info, " · "))
+	}
+	return ansi.Truncate(line, m.width, "…")
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestModel_summaryCounts(t *testing.T) {
+	m := treeModel()
+	m.files[0].unhealthy = 1
+	m.updates["/docker/apps/plex/compose.yaml"] = []string{"plexinc/pms-docker", "redis:7"}
+
+	want := []string{"4 stacks", "● 3 running", "◐ 0 partial", "○ 1 stopped", "✚ 1 unhealthy", "⬆ 2 updates"}
+	counts := m.summaryCounts()
+	if len(counts) != len(want) {
+		t.Fatalf("summaryCounts() = %+v", counts)
+	}
+	for i, c := range counts {
+		if c.text != want[i] {
+			t.Errorf("count %d = %q, want %q", i, c.text, want[i])
+		}
+	}
+}
+
+func TestModel_summaryFilter(t *testing.T) {
+	m := treeModel()
+	m.state = stateList
+	m.flat = true
+	m.files[0].unhealthy = 1
+	m.updates["/docker/apps/plex/compose.yaml"] = []string{"redis:7"}
+
+	// " 4 stacks   ● 3 running   ◐ 0 partial   ○ 1 stopped   ✚ 1 unhealthy"
+	tests := []struct {
+		x    int
+		want string
+		ok   bool
+	}{
+		{0, "", false},
+		{1, "", true},
+		{12, "running", true},
+		{24, "", false},
+		{27, "partial", true},
+		{54, "unhealthy", true},
+	}
+	for _, tt := range tests {
+		if got, ok := m.summaryFilterAt(tt.x); got != tt.want || ok != tt.ok {
+			t.Errorf("summaryFilterAt(%d) = %q, %v, want %q, %v", tt.x, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	next, _ := m.updateMouse(tea.MouseMsg{X: 54, Y: 0, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	m = next.(Model)
+	if got := m.visible(); len(got) != 1 || got[0] != 0 {
+		t.Errorf("clicking unhealthy shows %v, want grafana only", got)
+	}
+
+	m.summaryFocus = true
+	m.summaryCursor = 5
+	next, _ = m.updateSummary(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if m.summaryFocus || m.statusFilter != "updates" {
+		t.Errorf("enter on updates: focus %v, filter %q", m.summaryFocus, m.statusFilter)
+	}
+	if got := m.visible(); len(got) != 1 || got[0] != 1 {
+		t.Errorf("updates filter shows %v, want plex only", got)
+	}
+
+	m.applySummaryFilter("updates")
+	if m.statusFilter != "" {
+		t.Errorf("applying the same filter again should clear it, got %q", m.statusFilter)
+	}
+	m.statusFilter = "unhealthy"
+	m.cycleStatusFilter()
+	if m.statusFilter != "" {
+		t.Errorf("cycling from a summary filter = %q, want everything", m.statusFilter)
+	}
+}
@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// theme names the colors the TUI is drawn with, as ANSI-256 numbers or hex
+// values. An empty color leaves the terminal's own.
+type theme map[string]string
+
+var themes = map[string]theme{
+	"dark": {
+		"accent":   "212",
+		"text":     "252",
+		"dim":      "240",
+		"faint":    "238",
+		"muted":    "244",
+		"selected": "236",
+		"error":    "196",
+		"warning":  "214",
+		"success":  "76",
+		"info":     "39",
+		"toast-fg": "231",
+		"toast-bg": "124",
+		"string":   "114",
+		"anchor":   "177",
+	},
+	"light": {
+		"accent":   "162",
+		"text":     "235",
+		"dim":      "245",
+		"faint":    "250",
+		"muted":    "243",
+		"selected": "254",
+		"error":    "160",
+		"warning":  "130",
+		"success":  "28",
+		"info":     "25",
+		"toast-fg": "231",
+		"toast-bg": "160",
+		"string":   "28",
+		"anchor":   "90",
+	},
+}
+
+// loadTheme returns a built-in theme with colors overridden by name. With
+// noColor, as when NO_COLOR is set, every color is left empty. The
+// default theme is dark.
+func loadTheme(name string, colors map[string]string, noColor bool) (theme, error) {
+	if name == "" {
+		name = "dark"
+	}
+	base, ok := themes[name]
+	if !ok {
+		return themes["dark"], fmt.Errorf("theme: unknown theme %q (want dark or light)", name)
+	}
+	t := make(theme, len(base))
+	for slot, color := range base {
+		t[slot] = color
+	}
+	var unknown []string
+	for slot, color := range colors {
+		if _, ok := t[slot]; !ok {
+			unknown = append(unknown, slot)
+			continue
+		}
+		t[slot] = color
+	}
+	if noColor {
+		for slot := range t {
+			t[slot] = ""
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return t, fmt.Errorf("colors: unknown color %s", strings.Join(unknown, ", "))
+	}
+	return t, nil
+}
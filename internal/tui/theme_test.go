@@ -0,0 +1,40 @@
+package tui
+
+import "testing"
+
+func Test_loadTheme(t *testing.T) {
+	th, err := loadTheme("light", map[string]string{"accent": "#ff00aa"}, false)
+	if err != nil {
+		t.Fatalf("loadTheme() error = %v", err)
+	}
+	if th["accent"] != "#ff00aa" || th["error"] != themes["light"]["error"] {
+		t.Errorf("loadTheme() = %v", th)
+	}
+	if themes["light"]["accent"] == "#ff00aa" {
+		t.Errorf("loadTheme() changed the built-in theme")
+	}
+
+	th, _ = loadTheme("", map[string]string{"accent": "#ff00aa"}, true)
+	for slot, color := range th {
+		if color != "" {
+			t.Errorf("NO_COLOR left %s = %q", slot, color)
+		}
+	}
+
+	if _, err := loadTheme("solarized", nil, false); err == nil {
+		t.Errorf("loadTheme() accepted an unknown theme")
+	}
+	if _, err := loadTheme("dark", map[string]string{"accnet": "1"}, false); err == nil {
+		t.Errorf("loadTheme() accepted an unknown color")
+	}
+}
+
+func Test_themesComplete(t *testing.T) {
+	for name, th := range themes {
+		for slot := range themes["dark"] {
+			if _, ok := th[slot]; !ok {
+				t.Errorf("theme %s is missing %s", name, slot)
+			}
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+type rowKind int
+
+const (
+	rowFile rowKind = iota
+	rowDir
+	rowService
+)
+
+// listRow is one line of the stack list: a compose file, a directory node or
+// a service of an expanded compose file.
+type listRow struct {
+	kind    rowKind
+	file    int    // index into m.files for file and service rows
+	dir     string // directory relative to DOCKER_DIR for dir rows
+	service string // service name for service rows
+	depth   int
+}
+
+// relDir returns a file's directory relative to DOCKER_DIR, or "" at the root.
+func (m Model) relDir(path string) string {
+	dir := filepath.Dir(path)
+	if m.root != "" {
+		if rel, err := filepath.Rel(m.root, dir); err == nil {
+			dir = rel
+		}
+	}
+	if dir == "." {
+		return ""
+	}
+	return filepath.ToSlash(dir)
+}
+
+// rows returns the list rows for the filtered files. In tree view files are
+// nested under their directories and collapsed directories hide their children.
+func (m Model) rows() []listRow {
+	vis := m.visible()
+	rows := make([]listRow, 0, len(vis))
+	if m.flat {
+		for _, i := range vis {
+			rows = m.appendFileRows(rows, i, 0)
+		}
+		return rows
+	}
+
+	emitted := make(map[string]bool)
+	for _, i := range vis {
+		dir := m.relDir(m.files[i].path)
+		var parts []string
+		if dir != "" {
+			parts = strings.Split(dir, "/")
+		}
+		hidden := false
+		for d := range parts {
+			prefix := strings.Join(parts[:d+1], "/")
+			if !emitted[prefix] {
+				emitted[prefix] = true
+				rows = append(rows, listRow{kind: rowDir, dir: prefix, depth: d})
+			}
+			if m.collapsed[prefix] {
+				hidden = true
+				break
+			}
+		}
+		if !hidden {
+			rows = m.appendFileRows(rows, i, len(parts))
+		}
+	}
+	return rows
+}
+
+// appendFileRows appends a file row followed by its service rows if expanded.
+func (m Model) appendFileRows(rows []listRow, file, depth int) []listRow {
+	rows = append(rows, listRow{kind: rowFile, file: file, depth: depth})
+	if f := m.files[file]; m.expanded[f.path] {
+		for _, svc := range m.serviceNames(f) {
+			rows = append(rows, listRow{kind: rowService, file: file, service: svc, depth: depth + 1})
+		}
+	}
+	return rows
+}
+
+// filesUnder returns the indexes of the filtered files beneath dir.
+func (m Model) filesUnder(dir string) []int {
+	var idx []int
+	for _, i := range m.visible() {
+		d := m.relDir(m.files[i].path)
+		if d == dir || strings.HasPrefix(d, dir+"/") {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// currentRow returns the row under the cursor.
+func (m Model) currentRow() (listRow, bool) {
+	rows := m.rows()
+	if m.cursor < 0 || m.cursor >= len(rows) {
+		return listRow{}, false
+	}
+	return rows[m.cursor], true
+}
+
+// toggleCollapse expands or collapses the directory under the cursor.
+func (m *Model) toggleCollapse(collapse bool) {
+	row, ok := m.currentRow()
+	if !ok || row.kind == rowService {
+		return
+	}
+	if row.kind == rowFile {
+		// Collapsing from a file folds its parent directory.
+		dir := m.relDir(m.files[row.file].path)
+		if !collapse || dir == "" || m.flat {
+			return
+		}
+		m.collapsed[dir] = true
+		for i, r := range m.rows() {
+			if r.kind == rowDir && r.dir == dir {
+				m.cursor = i
+				break
+			}
+		}
+		return
+	}
+	if collapse {
+		m.collapsed[row.dir] = true
+	} else {
+		delete(m.collapsed, row.dir)
+	}
+}
+
+// aggregateStatus summarises the statuses of files: a shared status if they
+// all agree, otherwise partial.
+func (m Model) aggregateStatus(idx []int) string {
+	status := ""
+	for _, i := range idx {
+		s := m.files[i].status
+		switch {
+		case status == "":
+			status = s
+		case status != s:
+			return "partial"
+		}
+	}
+	if status == "" {
+		return "unknown"
+	}
+	return status
+}
+
+func (m Model) renderDirRow(row listRow) string {
+	idx := m.filesUnder(row.dir)
+	arrow := "▾"
+	if m.collapsed[row.dir] {
+		arrow = "▸"
+	}
+	mark := " "
+	if len(idx) > 0 {
+		mark = "*"
+		for _, i := range idx {
+			if !m.marked[m.files[i].path] {
+				mark = " "
+				break
+			}
+		}
+	}
+	return fmt.Sprintf("%s%s%s %s %s/ %s", mark, strings.Repeat("  ", row.depth), arrow,
+		statusIndicator(m.aggregateStatus(idx)), filepath.Base(row.dir), dimStyle.Render(fmt.Sprintf("(%d)", len(idx))))
+}
@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+func treeModel() Model {
+	m := New(Options{})
+	m.root = "/docker"
+	m.files = []composeFile{
+		{path: "/docker/apps/grafana/compose.yaml", status: "running"},
+		{path: "/docker/apps/plex/compose.yaml", status: "stopped"},
+		{path: "/docker/traefik/compose.yaml", status: "running"},
+		{path: "/docker/root.yaml", status: "running"},
+	}
+	return m
+}
+
+func TestModel_rows(t *testing.T) {
+	m := treeModel()
+
+	describe := func(rows []listRow) []string {
+		var out []string
+		for _, r := range rows {
+			if r.kind == rowDir {
+				out = append(out, "dir:"+r.dir)
+			} else {
+				out = append(out, m.files[r.file].path)
+			}
+		}
+		return out
+	}
+
+	want := []string{
+		"dir:apps",
+		"dir:apps/grafana",
+		"/docker/apps/grafana/compose.yaml",
+		"dir:apps/plex",
+		"/docker/apps/plex/compose.yaml",
+		"dir:traefik",
+		"/docker/traefik/compose.yaml",
+		"/docker/root.yaml",
+	}
+	if got := describe(m.rows()); !reflect.DeepEqual(got, want) {
+		t.Errorf("rows() = %v, want %v", got, want)
+	}
+
+	m.collapsed["apps"] = true
+	want = []string{"dir:apps", "dir:traefik", "/docker/traefik/compose.yaml", "/docker/root.yaml"}
+	if got := describe(m.rows()); !reflect.DeepEqual(got, want) {
+		t.Errorf("rows() with apps collapsed = %v, want %v", got, want)
+	}
+
+	m.cursor = 0
+	wantTargets := []ahab.Target{{File: "/docker/apps/grafana/compose.yaml"}, {File: "/docker/apps/plex/compose.yaml"}}
+	if got := m.targets(); !reflect.DeepEqual(got, wantTargets) {
+		t.Errorf("targets() on folder = %v, want %v", got, wantTargets)
+	}
+	if got := m.aggregateStatus(m.filesUnder("apps")); got != "partial" {
+		t.Errorf("aggregateStatus(apps) = %q, want partial", got)
+	}
+	if got := m.aggregateStatus(m.filesUnder("traefik")); got != "running" {
+		t.Errorf("aggregateStatus(traefik) = %q, want running", got)
+	}
+}
@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+// updateSem bounds how many stacks are checked against the registry at once.
+var updateSem = make(chan struct{}, 2)
+
+type updatesMsg struct {
+	path   string
+	images []string
+	err    error
+}
+type updateTickMsg struct{}
+
+type dockerHostMsg struct{ host string }
+
+// checkUpdates looks for newer images of each file in the registry, unless
+// the update check is disabled.
+func (m Model) checkUpdates(files []composeFile) tea.Cmd {
+	if m.config.UpdateCheck <= 0 {
+		return nil
+	}
+	cmds := make([]tea.Cmd, len(files))
+	for i, f := range files {
+		path := f.path
+		cmds[i] = func() tea.Msg {
+			updateSem <- struct{}{}
+			defer func() { <-updateSem }()
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			defer cancel()
+			images, err := ahab.CheckUpdates(ctx, path)
+			return updatesMsg{path: path, images: images, err: err}
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// checkUpdatesFor re-checks the stacks an action ran on.
+func (m Model) checkUpdatesFor(targets []ahab.Target) tea.Cmd {
+	var files []composeFile
+	for _, t := range targets {
+		files = append(files, composeFile{path: t.File})
+	}
+	return m.checkUpdates(files)
+}
+
+func (m Model) updateTickCmd() tea.Cmd {
+	if m.config.UpdateCheck <= 0 {
+		return nil
+	}
+	return tea.Tick(time.Duration(m.config.UpdateCheck), func(time.Time) tea.Msg {
+		return updateTickMsg{}
+	})
+}
+
+// setUpdates records a stack's outdated images. A failed check keeps what
+// was known, and only the first failure is shown, since a registry that is
+// unreachable fails for every stack.
+func (m *Model) setUpdates(msg updatesMsg) {
+	if msg.err != nil && len(msg.images) == 0 {
+		if !m.updateErrShown {
+			m.updateErrShown = true
+			m.statusMsg = "update check: " + msg.err.Error()
+		}
+		return
+	}
+	if len(msg.images) == 0 {
+		delete(m.updates, msg.path)
+	} else {
+		m.updates[msg.path] = msg.images
+	}
+	m.resort()
+}
+
+// fetchDockerHost finds the daemon the TUI talks to.
+func fetchDockerHost() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		host, err := ahab.DockerHost(ctx)
+		if err != nil {
+			return nil
+		}
+		return dockerHostMsg{host}
+	}
+}
@@ -0,0 +1,251 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+const (
+	// watchDebounce is how long DOCKER_DIR must be quiet before the stacks
+	// are rediscovered.
+	watchDebounce = 500 * time.Millisecond
+	// diffDuration is how long added and removed stacks stay highlighted.
+	diffDuration = 10 * time.Second
+	// maxRemovedRows caps how much of the list pane removed stacks may use.
+	maxRemovedRows = 5
+)
+
+type watchStartedMsg struct {
+	changes <-chan struct{}
+	cancel  context.CancelFunc
+}
+type watchClosedMsg struct{}
+type watchRetryMsg struct{}
+type filesChangedMsg struct{}
+type filesRediscoveredMsg struct {
+	files []composeFile
+	err   error
+}
+type diffExpireMsg struct{ gen int }
+
+// discoverFiles finds the compose files in DOCKER_DIR.
+func discoverFiles() (string, []composeFile, error) {
+	root, err := ahab.DockerDir()
+	if err != nil {
+		return "", nil, err
+	}
+	infos, err := ahab.FindComposeFilesForTUI()
+	if err != nil {
+		return "", nil, err
+	}
+	var cfs []composeFile
+	for _, info := range infos {
+		cfs = append(cfs, composeFile{
+			path:   info.Path,
+			status: "unknown",
+		})
+	}
+	return root, cfs, nil
+}
+
+// watchFiles starts watching DOCKER_DIR for stacks being added or removed.
+func watchFiles() tea.Cmd {
+	return func() tea.Msg {
+		root, err := ahab.DockerDir()
+		if err != nil {
+			return watchClosedMsg{}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		changes, err := ahab.WatchDir(ctx, root, watchDebounce)
+		if err != nil {
+			cancel()
+			return watchClosedMsg{}
+		}
+		return watchStartedMsg{changes: changes, cancel: cancel}
+	}
+}
+
+// waitForChange blocks until DOCKER_DIR changes.
+func waitForChange(changes <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		if _, ok := <-changes; !ok {
+			return watchClosedMsg{}
+		}
+		return filesChangedMsg{}
+	}
+}
+
+func rediscoverFiles() tea.Cmd {
+	return func() tea.Msg {
+		_, files, err := discoverFiles()
+		return filesRediscoveredMsg{files: files, err: err}
+	}
+}
+
+func (m *Model) stopWatch() {
+	if m.watchCancel != nil {
+		m.watchCancel()
+		m.watchCancel = nil
+	}
+}
+
+// rediscovered updates the list after DOCKER_DIR changed, highlighting the
+// stacks that were added or removed for a while.
+func (m *Model) rediscovered(msg filesRediscoveredMsg) tea.Cmd {
+	if m.state != stateList {
+		return nil
+	}
+	if msg.err != nil {
+		return m.reportError("", "discover", msg.err)
+	}
+	added, removed := m.mergeFiles(msg.files)
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	m.added = make(map[string]bool)
+	for _, f := range added {
+		m.added[f.path] = true
+	}
+	m.removed = removed
+	m.diffGen++
+	gen := m.diffGen
+
+	var parts []string
+	if len(added) > 0 {
+		parts = append(parts, fmt.Sprintf("%d added", len(added)))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, fmt.Sprintf("%d removed", len(removed)))
+	}
+	m.statusMsg = "stacks changed: " + strings.Join(parts, ", ")
+	return tea.Batch(
+		m.startRefresh(),
+		fetchOrphans(),
+		loadMeta(added),
+		tea.Tick(diffDuration, func(time.Time) tea.Msg { return diffExpireMsg{gen} }),
+	)
+}
+
+// mergeFiles replaces the list with rediscovered files. Files still there
+// keep their status and metadata, state about removed files is dropped, and
+// the cursor stays on the same row.
+func (m *Model) mergeFiles(files []composeFile) (added []composeFile, removed []string) {
+	key := m.cursorKey()
+
+	known := make(map[string]composeFile, len(m.files))
+	for _, f := range m.files {
+		known[f.path] = f
+	}
+	for i, f := range files {
+		if old, ok := known[f.path]; ok {
+			files[i] = old
+			delete(known, f.path)
+		} else {
+			added = append(added, f)
+		}
+	}
+	for _, f := range m.files {
+		if _, gone := known[f.path]; !gone {
+			continue
+		}
+		removed = append(removed, f.path)
+		m.forgetFile(f.path)
+	}
+	m.files = files
+	m.resort()
+
+	if !m.restoreCursor(key) {
+		m.clampCursor()
+		m.selectionChanged()
+	}
+	return added, removed
+}
+
+// forgetFile drops the state kept about a file that is no longer listed. A
+// job still running on it keeps the file's output, which the job's history
+// entry is taken from; setProgress forgets the rest once the job finishes.
+func (m *Model) forgetFile(path string) {
+	delete(m.marked, path)
+	delete(m.expanded, path)
+	delete(m.services, path)
+	delete(m.stackErrs, path)
+	delete(m.progress, path)
+	delete(m.stats, path)
+	delete(m.updates, path)
+	if cancel, ok := m.alertWatchers[path]; ok {
+		cancel()
+		delete(m.alertWatchers, path)
+	}
+	if _, running := m.jobs[path]; !running {
+		delete(m.output, path)
+	}
+}
+
+// rowKey identifies a row across changes to the list.
+func (m Model) rowKey(r listRow) string {
+	switch r.kind {
+	case rowDir:
+		return "dir:" + r.dir
+	case rowService:
+		return m.files[r.file].path + ":" + r.service
+	default:
+		return m.files[r.file].path
+	}
+}
+
+// cursorKey returns the key of the row under the cursor, or "" if there is
+// none.
+func (m Model) cursorKey() string {
+	if current, ok := m.currentRow(); ok {
+		return m.rowKey(current)
+	}
+	return ""
+}
+
+// restoreCursor moves the cursor to the row with key, reporting whether that
+// row is still in the list.
+func (m *Model) restoreCursor(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range m.rows() {
+		if m.rowKey(r) == key {
+			m.cursor = i
+			return true
+		}
+	}
+	return false
+}
+
+// removedRows is the number of list rows taken by removed stacks.
+func (m Model) removedRows() int {
+	if len(m.removed) == 0 {
+		return 0
+	}
+	return min(len(m.removed), maxRemovedRows) + 2
+}
+
+func (m Model) renderRemoved() string {
+	if len(m.removed) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n" + titleStyle.Render(fmt.Sprintf("removed (%d)", len(m.removed))) + "\n")
+	for i, path := range m.removed {
+		if i == maxRemovedRows {
+			break
+		}
+		name := filepath.Base(path)
+		if dir := m.relDir(path); dir != "" {
+			name = dir + "/" + name
+		}
+		b.WriteString(" " + failureStyle.Render("− "+name) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"testing"
+
+	ahab "github.com/josh-allan/ahab/pkg"
+)
+
+func TestModel_rediscovered(t *testing.T) {
+	m := treeModel()
+	m.state = stateList
+	m.flat = true
+	m.files[1].meta = ahab.ComposeMeta{Services: []string{"plex"}}
+	m.marked["/docker/traefik/compose.yaml"] = true
+	m.updates["/docker/traefik/compose.yaml"] = []string{"traefik:v3"}
+	m.outputFor("/docker/traefik/compose.yaml").append("pulled")
+	m.startJob("pull", ahab.Target{File: "/docker/root.yaml"})
+	m.outputFor("/docker/root.yaml").append("pulling")
+	m.cursor = 1 // apps/plex
+
+	m.rediscovered(filesRediscoveredMsg{files: []composeFile{
+		{path: "/docker/apps/grafana/compose.yaml", status: "unknown"},
+		{path: "/docker/apps/immich/compose.yaml", status: "unknown"},
+		{path: "/docker/apps/plex/compose.yaml", status: "unknown"},
+		{path: "/docker/root.yaml", status: "unknown"},
+	}})
+
+	if f, _ := m.selected(); f.path != "/docker/apps/plex/compose.yaml" {
+		t.Errorf("cursor moved to %s, want it to stay on plex", f.path)
+	}
+	if f, _ := m.selected(); f.status != "stopped" || len(f.meta.Services) != 1 {
+		t.Errorf("plex lost its state: %+v", f)
+	}
+	if !m.added["/docker/apps/immich/compose.yaml"] || len(m.added) != 1 {
+		t.Errorf("added = %v, want immich", m.added)
+	}
+	if len(m.removed) != 1 || m.removed[0] != "/docker/traefik/compose.yaml" {
+		t.Errorf("removed = %v, want traefik", m.removed)
+	}
+	if m.marked["/docker/traefik/compose.yaml"] {
+		t.Errorf("a removed stack is still marked")
+	}
+	if _, ok := m.updates["/docker/traefik/compose.yaml"]; ok {
+		t.Errorf("a removed stack still counts as having updates")
+	}
+	if _, ok := m.output["/docker/traefik/compose.yaml"]; ok {
+		t.Errorf("a removed stack's output was kept")
+	}
+	if m.statusMsg != "stacks changed: 1 added, 1 removed" {
+		t.Errorf("statusMsg = %q", m.statusMsg)
+	}
+
+	m.rediscovered(filesRediscoveredMsg{files: []composeFile{
+		{path: "/docker/apps/grafana/compose.yaml", status: "unknown"},
+	}})
+	if m.cursor != 0 {
+		t.Errorf("cursor = %d after its file was removed, want it clamped to 0", m.cursor)
+	}
+
+	// A stack removed while a job runs on it keeps its output for the job's
+	// history entry, and is forgotten once the job finishes.
+	if _, ok := m.output["/docker/root.yaml"]; !ok {
+		t.Fatalf("a running job's output was dropped")
+	}
+	m.setProgress("pull", ahab.FileProgress{File: "/docker/root.yaml", Done: true})
+	if len(m.history) != 1 || len(m.history[0].Output) != 1 {
+		t.Errorf("history = %+v, want the job with its output", m.history)
+	}
+	if _, ok := m.output["/docker/root.yaml"]; ok {
+		t.Errorf("output kept after the removed stack's job finished")
+	}
+	if _, ok := m.progress["/docker/root.yaml"]; ok {
+		t.Errorf("progress kept after the removed stack's job finished")
+	}
+}
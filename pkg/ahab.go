@@ -20,6 +20,10 @@ var yamlRegex = regexp.MustCompile(`\.ya?ml$`)
 
 const maxConcurrentCommands = 4
 
+// composeSem is shared by every batch, so concurrent batches together stay
+// within maxConcurrentCommands.
+var composeSem = make(chan struct{}, maxConcurrentCommands)
+
 type ignoreRules struct {
 	exact    map[string]struct{}
 	prefixes []string
@@ -74,6 +78,16 @@ func getDockerDir() (string, error) {
 	return "", fmt.Errorf("DOCKER_DIR environment variable is not set")
 }
 
+// DockerDir returns the directory named by the DOCKER_DIR environment variable.
+func DockerDir() (string, error) {
+	return getDockerDir()
+}
+
+// skipDir reports whether discovery, and so watching, skips a directory.
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "kube" || name == "node_modules"
+}
+
 func findYAMLFiles(dir string) ([]string, error) {
 	var files []string
 	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
@@ -81,7 +95,7 @@ func findYAMLFiles(dir string) ([]string, error) {
 			return err
 		}
 		if d.IsDir() {
-			if strings.HasPrefix(d.Name(), ".") || d.Name() == "kube" || d.Name() == "node_modules" {
+			if path != dir && skipDir(d.Name()) {
 				return filepath.SkipDir
 			}
 			return nil
@@ -161,43 +175,107 @@ func FindComposeFilesForTUI() ([]ComposeFileInfo, error) {
 
 // GetComposeStatus runs docker compose ps and returns "running", "stopped", "partial", or "unknown".
 func GetComposeStatus(file string) string {
-	cmd := exec.Command("docker", "compose", "-f", file, "ps", "--format", "json")
+	return GetComposeStatusContext(context.Background(), file)
+}
+
+// GetComposeStatusContext is like GetComposeStatus but stops waiting for docker when ctx is cancelled.
+func GetComposeStatusContext(ctx context.Context, file string) string {
+	return GetComposeState(ctx, file).Status
+}
+
+// ComposeState is a stack's status and how many of its containers fail
+// their health check.
+type ComposeState struct {
+	Status    string
+	Unhealthy int
+}
+
+// GetComposeState runs docker compose ps and summarizes the stack's containers.
+func GetComposeState(ctx context.Context, file string) ComposeState {
+	cmd := exec.CommandContext(ctx, "docker", "compose", "-f", file, "ps", "--format", "json")
 	out, err := cmd.Output()
 	if err != nil {
-		return "unknown"
+		return ComposeState{Status: "unknown"}
 	}
-	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
-	if len(lines) == 0 || lines[0] == "" || lines[0] == "[]" {
-		return "stopped"
+	return summarizeState(parsePs(out))
+}
+
+func summarizeState(containers []containerPs) ComposeState {
+	var running, unhealthy int
+	for _, c := range containers {
+		if c.State == "running" {
+			running++
+		}
+		if c.Health == "unhealthy" {
+			unhealthy++
+		}
 	}
+	return ComposeState{Status: summarizeStatus(running, len(containers)), Unhealthy: unhealthy}
+}
 
-	type containerPs struct {
-		State string `json:"State"`
+// GetServiceStatuses runs docker compose ps --all and returns the status of
+// each service that has containers, keyed by service name.
+func GetServiceStatuses(ctx context.Context, file string) (map[string]string, error) {
+	cmd := exec.CommandContext(ctx, "docker", "compose", "-f", file, "ps", "--all", "--format", "json")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker compose ps: %w", err)
+	}
+	type count struct{ running, total int }
+	counts := make(map[string]count)
+	for _, c := range parsePs(out) {
+		n := counts[c.Service]
+		n.total++
+		if c.State == "running" {
+			n.running++
+		}
+		counts[c.Service] = n
 	}
+	statuses := make(map[string]string, len(counts))
+	for svc, n := range counts {
+		statuses[svc] = summarizeStatus(n.running, n.total)
+	}
+	return statuses, nil
+}
 
-	var running, total int
-	for _, line := range lines {
+type containerPs struct {
+	Service string `json:"Service"`
+	State   string `json:"State"`
+	Health  string `json:"Health"`
+}
+
+// parsePs decodes docker compose ps --format json output, which is either
+// one JSON object per line or, on older versions, a single JSON array.
+func parsePs(out []byte) []containerPs {
+	var containers []containerPs
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		line = strings.TrimSpace(line)
 		if line == "" || line == "[]" {
 			continue
 		}
+		if strings.HasPrefix(line, "[") {
+			var list []containerPs
+			if err := json.Unmarshal([]byte(line), &list); err == nil {
+				containers = append(containers, list...)
+			}
+			continue
+		}
 		var c containerPs
 		if err := json.Unmarshal([]byte(line), &c); err != nil {
 			continue
 		}
-		total++
-		if c.State == "running" {
-			running++
-		}
+		containers = append(containers, c)
 	}
-	if total == 0 {
+	return containers
+}
+
+func summarizeStatus(running, total int) string {
+	if total == 0 || running == 0 {
 		return "stopped"
 	}
 	if running == total {
 		return "running"
 	}
-	if running == 0 {
-		return "stopped"
-	}
 	return "partial"
 }
 
@@ -211,36 +289,119 @@ func execCompose(ctx context.Context, stdout, stderr io.Writer, file string, arg
 	cmd := exec.CommandContext(ctx, "docker", cmdArgs...)
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
-	fmt.Printf("Running: docker %s\n", strings.Join(cmdArgs, " "))
+	fmt.Fprintf(stdout, "Running: docker %s\n", strings.Join(cmdArgs, " "))
 	return cmd.Run()
 }
 
-func runOnFiles(ctx context.Context, files []string, action string, cmdArgs []string) error {
-	fmt.Printf("%s docker compose for each file...\n", action)
-	sem := make(chan struct{}, maxConcurrentCommands)
+// Target is a compose file, optionally narrowed to some of its services.
+type Target struct {
+	File     string
+	Services []string
+}
+
+// FileProgress reports that a target in a bulk compose run has started or finished.
+type FileProgress struct {
+	File     string
+	Services []string
+	Done     bool
+	Err      error
+}
+
+// ExecComposeEach runs docker compose with args on each file, starting them in
+// order with at most maxConcurrentCommands running at once. progress, if not
+// nil, is called as each file starts and finishes.
+func ExecComposeEach(ctx context.Context, stdout, stderr io.Writer, files []string, progress func(FileProgress), args ...string) error {
+	targets := make([]Target, len(files))
+	for i, f := range files {
+		targets[i] = Target{File: f}
+	}
+	return ExecComposeTargets(ctx, stdout, stderr, targets, progress, args...)
+}
+
+// ExecComposeTargets is like ExecComposeEach but appends each target's
+// services to args, so only those services are acted on.
+func ExecComposeTargets(ctx context.Context, stdout, stderr io.Writer, targets []Target, progress func(FileProgress), args ...string) error {
+	return execTargets(func(Target) (context.Context, io.Writer, io.Writer) { return ctx, stdout, stderr }, targets, progress, args)
+}
+
+// ExecComposeTargetsTo is like ExecComposeTargets, but each target runs with
+// the context and writer returned by setup, so targets can be cancelled and
+// their output captured individually. The writer gets both stdout and stderr.
+func ExecComposeTargetsTo(setup func(Target) (context.Context, io.Writer), targets []Target, progress func(FileProgress), args ...string) error {
+	return execTargets(func(t Target) (context.Context, io.Writer, io.Writer) {
+		ctx, w := setup(t)
+		return ctx, w, w
+	}, targets, progress, args)
+}
+
+func execTargets(setup func(Target) (context.Context, io.Writer, io.Writer), targets []Target, progress func(FileProgress), args []string) error {
+	if progress == nil {
+		progress = func(FileProgress) {}
+	}
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	var errs []error
+	fail := func(t Target, err error) {
+		mu.Lock()
+		errs = append(errs, fmt.Errorf("%s: %w", t, err))
+		mu.Unlock()
+	}
 
-	for _, file := range files {
-		sem <- struct{}{}
+	for _, target := range targets {
+		ctx, stdout, stderr := setup(target)
+		// A target cancelled while waiting for a slot never starts.
+		if err := acquireCompose(ctx); err != nil {
+			fail(target, err)
+			progress(FileProgress{File: target.File, Services: target.Services, Done: true, Err: err})
+			continue
+		}
 		wg.Add(1)
-		go func(f string) {
+		go func(t Target) {
 			defer wg.Done()
-			defer func() { <-sem }()
-			if err := execCompose(ctx, os.Stdout, os.Stderr, f, cmdArgs...); err != nil {
-				mu.Lock()
-				errs = append(errs, fmt.Errorf("%s: %w", f, err))
-				mu.Unlock()
+			defer func() { <-composeSem }()
+			progress(FileProgress{File: t.File, Services: t.Services})
+			cmdArgs := append(append([]string{}, args...), t.Services...)
+			err := execCompose(ctx, stdout, stderr, t.File, cmdArgs...)
+			if err != nil {
+				fail(t, err)
 			}
-		}(file)
+			progress(FileProgress{File: t.File, Services: t.Services, Done: true, Err: err})
+		}(target)
 	}
 
 	wg.Wait()
 	return errors.Join(errs...)
 }
 
-func runAction(action string, cmdArgs ...string) error {
+// acquireCompose waits for a slot to run docker compose in, unless ctx is
+// done first.
+func acquireCompose(ctx context.Context) error {
+	select {
+	case composeSem <- struct{}{}:
+		if err := ctx.Err(); err != nil {
+			<-composeSem
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// String renders a target as "file" or "file:svc1,svc2".
+func (t Target) String() string {
+	if len(t.Services) == 0 {
+		return t.File
+	}
+	return t.File + ":" + strings.Join(t.Services, ",")
+}
+
+func runOnTargets(ctx context.Context, targets []Target, action string, cmdArgs []string) error {
+	fmt.Printf("%s docker compose for each file...\n", action)
+	return ExecComposeTargets(ctx, os.Stdout, os.Stderr, targets, nil, cmdArgs...)
+}
+
+func runAction(action string, args []string, cmdArgs ...string) error {
 	ctx := context.Background()
 	files, err := findComposeFiles(action)
 	if err != nil {
@@ -249,14 +410,28 @@ func runAction(action string, cmdArgs ...string) error {
 	if len(files) == 0 {
 		return nil
 	}
-	return runOnFiles(ctx, files, action, cmdArgs)
+	dir, err := getDockerDir()
+	if err != nil {
+		return err
+	}
+	targets, err := resolveTargets(dir, files, args)
+	if err != nil {
+		return err
+	}
+	return runOnTargets(ctx, targets, action, cmdArgs)
 }
 
-func RunAllCompose() error      { return runAction("start", "up", "-d") }
-func UpdateAllCompose() error   { return runAction("update", "pull") }
-func StopAllCompose() error     { return runAction("stop", "stop") }
-func StopAllComposeDown() error { return runAction("down", "down") }
-func RestartAllCompose() error  { return runAction("restart", "restart") }
+// The bulk actions below act on every discovered file, or only on the given
+// "stack" or "stack:service" targets.
+
+func RunAllCompose(targets ...string) error      { return runAction("start", targets, "up", "-d") }
+func UpdateAllCompose(targets ...string) error   { return runAction("update", targets, "pull") }
+func StopAllCompose(targets ...string) error     { return runAction("stop", targets, "stop") }
+func StopAllComposeDown(targets ...string) error { return runAction("down", targets, "down") }
+func RestartAllCompose(targets ...string) error  { return runAction("restart", targets, "restart") }
+func RecreateAllCompose(targets ...string) error {
+	return runAction("recreate", targets, "up", "-d", "--force-recreate")
+}
 
 func ListIgnoreFiles() error {
 	dir, err := getDockerDir()
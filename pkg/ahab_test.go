@@ -1,10 +1,15 @@
 package ahab
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -119,6 +124,11 @@ func Test_findYAMLFiles(t *testing.T) {
 			wantNames: []string{"another-service.yml", "compose.yaml", "docker.yaml", "valid-service.yaml"},
 			wantErr:   false,
 		},
+		{
+			name:      "searches the root directory even when its name would be skipped",
+			dir:       "./testdata/node_modules",
+			wantNames: []string{"package.yaml"},
+		},
 		{
 			name:    "non-existent directory returns error",
 			dir:     "./non_existent_dir",
@@ -196,3 +206,59 @@ func Test_getDockerDir(t *testing.T) {
 		})
 	}
 }
+
+func Test_execTargets_sharedLimit(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	script := fmt.Sprintf("#!/bin/sh\necho s >> %[1]s\nsleep 0.2\necho e >> %[1]s\n", log)
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var targets []Target
+	for i := range 6 {
+		targets = append(targets, Target{File: fmt.Sprintf("/docker/%d.yaml", i)})
+	}
+	// Two batches at once still share one limit.
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := ExecComposeTargets(context.Background(), io.Discard, io.Discard, targets, nil, "ps"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	running, peak := 0, 0
+	for _, line := range strings.Fields(string(data)) {
+		if line == "s" {
+			running++
+		} else {
+			running--
+		}
+		peak = max(peak, running)
+	}
+	if peak > maxConcurrentCommands {
+		t.Errorf("%d docker compose commands ran at once, want at most %d", peak, maxConcurrentCommands)
+	}
+}
+
+func Test_execTargets_cancelledWhileQueued(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var done []FileProgress
+	err := ExecComposeTargets(ctx, io.Discard, io.Discard, []Target{{File: "/docker/a.yaml"}}, func(p FileProgress) {
+		done = append(done, p)
+	}, "ps")
+	if err == nil || len(done) != 1 || !done[0].Done || done[0].Err != context.Canceled {
+		t.Errorf("cancelled target: err %v, progress %+v", err, done)
+	}
+}
@@ -0,0 +1,180 @@
+package ahab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// defaultAlertCooldown is how long a rule stays quiet after alerting when
+// it doesn't set a cooldown.
+const defaultAlertCooldown = 5 * time.Minute
+
+// AlertRule raises an alert when a log line matches Pattern.
+type AlertRule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+	// Stacks limits a rule from the config to these stacks; rules from
+	// x-ahab only apply to their own stack. Empty means every stack.
+	Stacks []string `json:"stacks"`
+	// Services limits the rule to these services. Empty means every service.
+	Services []string `json:"services"`
+	// Cooldown is how long the rule stays quiet after alerting, so a crash
+	// loop doesn't alert on every restart.
+	Cooldown Duration `json:"cooldown"`
+}
+
+func (r AlertRule) label() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Pattern
+}
+
+// Alert is a log line that matched an alert rule.
+type Alert struct {
+	Rule    string    `json:"rule"`
+	Stack   string    `json:"stack"`
+	Service string    `json:"service"`
+	Line    string    `json:"line"`
+	Time    time.Time `json:"time"`
+}
+
+func (a Alert) String() string {
+	stack := a.Stack
+	if a.Service != "" {
+		stack += "/" + a.Service
+	}
+	return fmt.Sprintf("%s in %s: %s", a.Rule, stack, a.Line)
+}
+
+type alertRule struct {
+	AlertRule
+	re   *regexp.Regexp
+	last time.Time
+}
+
+// Alerter matches the log lines of one stack against its alert rules. It is
+// not safe for concurrent use.
+type Alerter struct {
+	stack string
+	rules []*alertRule
+}
+
+// NewAlerter returns an Alerter for a compose file with the config rules
+// that apply to it and the rules from its own x-ahab extension. It returns
+// nil if no rules apply.
+func NewAlerter(root, file string, config, own []AlertRule) (*Alerter, error) {
+	a := &Alerter{stack: StackName(root, file)}
+	add := func(r AlertRule) error {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("alert %q: %w", r.label(), err)
+		}
+		a.rules = append(a.rules, &alertRule{AlertRule: r, re: re})
+		return nil
+	}
+	for _, r := range config {
+		if len(r.Stacks) > 0 && !slices.ContainsFunc(r.Stacks, func(s string) bool { return matchesStack(root, file, s) }) {
+			continue
+		}
+		if err := add(r); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range own {
+		if err := add(r); err != nil {
+			return nil, err
+		}
+	}
+	if len(a.rules) == 0 {
+		return nil, nil
+	}
+	return a, nil
+}
+
+// Check returns an alert for the first rule the line matches that isn't
+// cooling down.
+func (a *Alerter) Check(l LogLine, now time.Time) (Alert, bool) {
+	service := l.Service()
+	for _, r := range a.rules {
+		if len(r.Services) > 0 && !slices.Contains(r.Services, service) {
+			continue
+		}
+		if !r.re.MatchString(l.Text) {
+			continue
+		}
+		cooldown := time.Duration(r.Cooldown)
+		if cooldown == 0 {
+			cooldown = defaultAlertCooldown
+		}
+		if !r.last.IsZero() && now.Sub(r.last) < cooldown {
+			continue
+		}
+		r.last = now
+		at := l.Time
+		if at.IsZero() {
+			at = now
+		}
+		return Alert{Rule: r.label(), Stack: a.stack, Service: service, Line: l.Text, Time: at}, true
+	}
+	return Alert{}, false
+}
+
+// NotifierConfig says where alerts are sent besides the TUI.
+type NotifierConfig struct {
+	// Command is run with sh -c for each alert, with the alert in the
+	// AHAB_ALERT_RULE, AHAB_ALERT_STACK, AHAB_ALERT_SERVICE, AHAB_ALERT_LINE
+	// and AHAB_ALERT_TEXT environment variables.
+	Command string `json:"command"`
+	// Webhook is sent each alert as a JSON POST, with a "text" field holding
+	// a one-line summary.
+	Webhook string `json:"webhook"`
+}
+
+// Notify sends an alert to the configured notifiers.
+func Notify(ctx context.Context, cfg NotifierConfig, a Alert) error {
+	if cfg.Command != "" {
+		cmd := exec.CommandContext(ctx, "sh", "-c", cfg.Command)
+		cmd.Env = append(os.Environ(),
+			"AHAB_ALERT_RULE="+a.Rule,
+			"AHAB_ALERT_STACK="+a.Stack,
+			"AHAB_ALERT_SERVICE="+a.Service,
+			"AHAB_ALERT_LINE="+a.Line,
+			"AHAB_ALERT_TEXT="+a.String(),
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("notifier command: %w: %s", err, bytes.TrimSpace(out))
+		}
+	}
+	if cfg.Webhook != "" {
+		body, err := json.Marshal(struct {
+			Alert
+			Text string `json:"text"`
+		}{a, a.String()})
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.Webhook, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("notifier webhook: %w", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("notifier webhook: %s", resp.Status)
+		}
+	}
+	return nil
+}
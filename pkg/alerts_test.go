@@ -0,0 +1,55 @@
+package ahab
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAlerter(t *testing.T) {
+	config := []AlertRule{
+		{Name: "panic", Pattern: `panic:`},
+		{Name: "other stack", Pattern: `.`, Stacks: []string{"traefik"}},
+	}
+	own := []AlertRule{{Pattern: `database is locked`, Services: []string{"sonarr"}, Cooldown: Duration(time.Minute)}}
+	a, err := NewAlerter("/docker", "/docker/media/compose.yaml", config, own)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.rules) != 2 {
+		t.Fatalf("NewAlerter() kept %d rules, want 2", len(a.rules))
+	}
+
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		line string
+		at   time.Duration
+		want string // rule that alerts, "" for none
+	}{
+		{"match", "sonarr-1 | 2024-05-01T10:00:00Z database is locked", 0, "database is locked"},
+		{"cooling down", "sonarr-1 | 2024-05-01T10:00:10Z database is locked", 10 * time.Second, ""},
+		{"other service", "plex-1 | 2024-05-01T10:00:20Z database is locked", 20 * time.Second, ""},
+		{"cooled down", "sonarr-1 | 2024-05-01T10:01:00Z database is locked", time.Minute, "database is locked"},
+		{"config rule", "plex-1 | 2024-05-01T10:01:00Z panic: nil map", time.Minute, "panic"},
+		{"default cooldown", "plex-1 | 2024-05-01T10:03:00Z panic: nil map", 3 * time.Minute, ""},
+	}
+	for _, tt := range tests {
+		got, ok := a.Check(ParseLogLine(tt.line), now.Add(tt.at))
+		if tt.want == "" {
+			if ok {
+				t.Errorf("%s: Check() = %v, want no alert", tt.name, got)
+			}
+			continue
+		}
+		if !ok || got.Rule != tt.want || got.Stack != "media" {
+			t.Errorf("%s: Check() = %+v, %v, want rule %q in media", tt.name, got, ok, tt.want)
+		}
+	}
+
+	if a, err := NewAlerter("/docker", "/docker/web/compose.yaml", config[1:], nil); a != nil || err != nil {
+		t.Errorf("NewAlerter() with no matching rules = %v, %v, want nil", a, err)
+	}
+	if _, err := NewAlerter("/docker", "/docker/web/compose.yaml", []AlertRule{{Pattern: "("}}, nil); err == nil {
+		t.Error("NewAlerter() with invalid pattern: want error")
+	}
+}
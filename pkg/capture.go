@@ -0,0 +1,377 @@
+package ahab
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// currentLog is the file a stack's logs are captured to until it is rotated.
+const currentLog = "current.log"
+
+// captureRetryDelay is how long capture waits before following a stack's
+// logs again after the stream ends, e.g. because its containers were removed.
+const captureRetryDelay = 5 * time.Second
+
+// rotatingWriter appends lines to a log file, rotating it into a gzipped
+// archive once it grows too big or too old.
+type rotatingWriter struct {
+	dir     string
+	maxSize int64
+	maxAge  time.Duration
+	keep    int
+
+	f      *os.File
+	size   int64
+	opened time.Time
+}
+
+func openRotatingWriter(dir string, cfg CaptureConfig) (*rotatingWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	w := &rotatingWriter{
+		dir:     dir,
+		maxSize: int64(cfg.MaxSizeMB) << 20,
+		maxAge:  time.Duration(cfg.MaxAge),
+		keep:    cfg.Keep,
+	}
+	return w, w.open()
+}
+
+func (w *rotatingWriter) open() error {
+	f, err := os.OpenFile(filepath.Join(w.dir, currentLog), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.f, w.size, w.opened = f, info.Size(), time.Now()
+	if w.size > 0 {
+		// Appending to the file of an earlier run: its age counts from then,
+		// so a capture that is restarted often still rotates.
+		w.opened = firstLogTime(f.Name(), info.ModTime())
+	}
+	return nil
+}
+
+// firstLogTime returns the time of the first line of a log file, or
+// fallback if it has none.
+func firstLogTime(path string, fallback time.Time) time.Time {
+	f, err := os.Open(path)
+	if err != nil {
+		return fallback
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		return fallback
+	}
+	if t := ParseLogLine(scanner.Text()).Time; !t.IsZero() {
+		return t
+	}
+	return fallback
+}
+
+// writeLine appends a line, rotating first if the current file is full.
+func (w *rotatingWriter) writeLine(line string) error {
+	if w.size > 0 && w.due(int64(len(line)+1)) {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := w.f.WriteString(line + "\n")
+	w.size += int64(n)
+	return err
+}
+
+func (w *rotatingWriter) due(n int64) bool {
+	return w.maxSize > 0 && w.size+n > w.maxSize || w.maxAge > 0 && time.Since(w.opened) >= w.maxAge
+}
+
+// rotate compresses the current file into a timestamped archive, prunes old
+// archives and starts a new current file.
+func (w *rotatingWriter) rotate() error {
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	current := filepath.Join(w.dir, currentLog)
+	name := filepath.Join(w.dir, time.Now().UTC().Format("20060102T150405.000000000Z")+".log.gz")
+	if err := gzipFile(current, name); err != nil {
+		return err
+	}
+	if err := os.Remove(current); err != nil {
+		return err
+	}
+	if err := w.prune(); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+// prune removes the oldest archives beyond the number to keep.
+func (w *rotatingWriter) prune() error {
+	if w.keep <= 0 {
+		return nil
+	}
+	archives, err := filepath.Glob(filepath.Join(w.dir, "*.log.gz"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(archives)
+	var errs []error
+	for len(archives) > w.keep {
+		errs = append(errs, os.Remove(archives[0]))
+		archives = archives[1:]
+	}
+	return errors.Join(errs...)
+}
+
+func (w *rotatingWriter) Close() error {
+	return w.f.Close()
+}
+
+func gzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// captureDir returns the directory logs are captured to.
+func captureDir(cfg CaptureConfig) (string, error) {
+	if cfg.Dir != "" {
+		return cfg.Dir, nil
+	}
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "logs"), nil
+}
+
+// Capture follows the logs of the targeted stacks, or of the stacks listed
+// in the capture config, and writes them to a rotated log per stack until
+// interrupted or terminated, as systemd stops services. Streams are
+// restarted when they end, so logs survive containers being recreated.
+func Capture(targets ...string) error {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		targets = cfg.Capture.Stacks
+	}
+	if len(targets) == 0 {
+		return errors.New("no stacks to capture: pass targets or set capture.stacks in the config")
+	}
+	dir, err := captureDir(cfg.Capture)
+	if err != nil {
+		return err
+	}
+	root, resolved, err := resolveDiscovered(targets)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Printf("Capturing logs of %d stack(s) to %s\n", len(resolved), dir)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+	for _, target := range resolved {
+		wg.Add(1)
+		go func(t Target) {
+			defer wg.Done()
+			alert, err := captureAlerts(ctx, root, t, cfg)
+			if err == nil {
+				err = captureTarget(ctx, archiveDir(dir, root, t.File), t, cfg.Capture, alert)
+			}
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", t, err))
+				mu.Unlock()
+			}
+		}(target)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// captureAlerts returns a function that checks captured lines against the
+// target's alert rules and sends alerts to the notifier. Only lines logged
+// after capture started can alert.
+func captureAlerts(ctx context.Context, root string, t Target, cfg Config) (func(LogLine), error) {
+	meta, err := GetComposeMeta(ctx, t.File)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: reading x-ahab alerts: %v\n", t, err)
+	}
+	alerter, err := NewAlerter(root, t.File, cfg.Alerts, meta.Alerts)
+	if err != nil || alerter == nil {
+		return func(LogLine) {}, err
+	}
+	started := time.Now()
+	return func(l LogLine) {
+		if l.Time.Before(started) {
+			return
+		}
+		a, ok := alerter.Check(l, time.Now())
+		if !ok {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "alert: %s\n", a)
+		go func() {
+			if err := Notify(ctx, cfg.Notifier, a); err != nil {
+				fmt.Fprintf(os.Stderr, "alert: %v\n", err)
+			}
+		}()
+	}, nil
+}
+
+// captureTarget writes a target's logs to dir until ctx is done, passing
+// each new line to alert. Each new stream resumes from the newest line
+// already captured. docker compose logs interleaves containers without
+// sorting them, so only lines at or before that resume point are skipped as
+// already captured, not every line older than the newest one seen.
+func captureTarget(ctx context.Context, dir string, t Target, cfg CaptureConfig, alert func(LogLine)) error {
+	w, err := openRotatingWriter(dir, cfg)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	last, err := lastLogTime(dir)
+	if err != nil {
+		return err
+	}
+
+	for {
+		resume := last
+		args := []string{"logs", "--timestamps", "--follow"}
+		if !resume.IsZero() {
+			args = append(args, "--since", resume.Format(time.RFC3339Nano))
+		}
+		var werr error
+		err := readLogs(ctx, t, args, func(l LogLine) {
+			if werr != nil || !l.Time.IsZero() && !resume.IsZero() && !l.Time.After(resume) {
+				return
+			}
+			alert(l)
+			if l.Time.IsZero() {
+				werr = w.writeLine(l.Prefix + l.Text)
+				return
+			}
+			werr = w.writeLine(l.Prefix + l.Time.Format(time.RFC3339Nano) + " " + l.Text)
+			if l.Time.After(last) {
+				last = l.Time
+			}
+		})
+		if werr != nil {
+			return werr
+		}
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", t, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(captureRetryDelay):
+		}
+	}
+}
+
+// lastLogTime returns the newest timestamp in the latest of dir's logs to
+// have one, or the zero time if there is none. Containers are captured
+// interleaved, so the newest line isn't necessarily the last one.
+func lastLogTime(dir string) (time.Time, error) {
+	files, err := archiveFiles(dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var last time.Time
+	for i := len(files) - 1; i >= 0 && last.IsZero(); i-- {
+		err := readArchive(files[i], func(l LogLine) {
+			if l.Time.After(last) {
+				last = l.Time
+			}
+		})
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+	return last, nil
+}
+
+// archiveDir is the directory a compose file's logs are captured to, named
+// by its path relative to root without the extension. Each file is its own
+// stack, so files sharing a directory get their own logs.
+func archiveDir(dir, root, file string) string {
+	rel, err := filepath.Rel(root, file)
+	if err != nil {
+		rel = file
+	}
+	return filepath.Join(dir, strings.TrimSuffix(rel, filepath.Ext(rel)))
+}
+
+// archiveFiles returns a stack's archived logs, oldest first, followed by
+// its current log.
+func archiveFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.log.gz"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	current := filepath.Join(dir, currentLog)
+	if _, err := os.Stat(current); err == nil {
+		files = append(files, current)
+	}
+	return files, nil
+}
+
+// readArchive passes each line of an archived or current log to emit.
+func readArchive(path string, emit func(LogLine)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	return ReadLines(r, func(line string) { emit(ParseLogLine(line)) })
+}
@@ -0,0 +1,196 @@
+package ahab
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_rotatingWriter(t *testing.T) {
+	dir := t.TempDir()
+	w, err := openRotatingWriter(dir, CaptureConfig{Keep: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.maxSize = 64
+	lines := []string{
+		"web-1  | 2024-05-01T10:00:00Z first line of the first file",
+		"web-1  | 2024-05-01T10:00:01Z second file",
+		"web-1  | 2024-05-01T10:00:02Z third file",
+	}
+	for _, line := range lines {
+		if err := w.writeLine(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := archiveFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || filepath.Base(files[1]) != currentLog {
+		t.Fatalf("archiveFiles() = %v, want one archive and %s", files, currentLog)
+	}
+	var got []string
+	for _, f := range files {
+		if err := readArchive(f, func(l LogLine) { got = append(got, l.Text) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(got) != 2 || got[0] != "second file" || got[1] != "third file" {
+		t.Errorf("archived lines = %q, want the oldest file pruned", got)
+	}
+
+	last, err := lastLogTime(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 5, 1, 10, 0, 2, 0, time.UTC); !last.Equal(want) {
+		t.Errorf("lastLogTime() = %v, want %v", last, want)
+	}
+
+	if err := os.Remove(filepath.Join(dir, currentLog)); err != nil {
+		t.Fatal(err)
+	}
+	if last, _ := lastLogTime(dir); !last.Equal(time.Date(2024, 5, 1, 10, 0, 1, 0, time.UTC)) {
+		t.Errorf("lastLogTime() without current log = %v, want the archive's last line", last)
+	}
+}
+
+func Test_parseSince(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "10m", want: now.Add(-10 * time.Minute)},
+		{in: "2024-04-30T08:00:00Z", want: time.Date(2024, 4, 30, 8, 0, 0, 0, time.UTC)},
+		{in: "2024-04-30", want: time.Date(2024, 4, 30, 0, 0, 0, 0, time.Local)},
+		{in: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.in, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSince(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func Test_captureTarget_services(t *testing.T) {
+	bin := t.TempDir()
+	// Containers come out interleaved and unsorted, as with docker compose
+	// logs; the resumed stream repeats the line at --since.
+	script := `#!/bin/sh
+case "$*" in
+*--since*)
+	echo "web-1  | 2024-05-01T10:00:03Z c"
+	echo "db-1   | 2024-05-01T10:00:04Z d2"
+	echo "web-1  | 2024-05-01T10:00:05Z e"
+	;;
+*)
+	echo "web-1  | 2024-05-01T10:00:02Z b"
+	echo "db-1   | 2024-05-01T10:00:01Z a"
+	echo "web-1  | 2024-05-01T10:00:03Z c"
+	echo "db-1   | 2024-05-01T10:00:02Z d"
+	;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	dir := t.TempDir()
+
+	capture := func(want int) []string {
+		t.Helper()
+		ctx, cancel := context.WithCancel(context.Background())
+		errc := make(chan error, 1)
+		go func() {
+			errc <- captureTarget(ctx, dir, Target{File: "/docker/app/compose.yaml"}, CaptureConfig{}, func(LogLine) {})
+		}()
+		var texts []string
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			data, _ := os.ReadFile(filepath.Join(dir, currentLog))
+			texts = nil
+			for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+				if line != "" {
+					texts = append(texts, ParseLogLine(line).Text)
+				}
+			}
+			if len(texts) >= want {
+				break
+			}
+		}
+		cancel()
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+		return texts
+	}
+
+	if got, want := capture(4), []string{"b", "a", "c", "d"}; !slices.Equal(got, want) {
+		t.Errorf("first capture = %v, want %v", got, want)
+	}
+	if got, want := capture(6), []string{"b", "a", "c", "d", "d2", "e"}; !slices.Equal(got, want) {
+		t.Errorf("resumed capture = %v, want %v", got, want)
+	}
+}
+
+func Test_archiveDir(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"/docker/media/compose.yaml", "/logs/media/compose"},
+		{"/docker/media/extras.yml", "/logs/media/extras"},
+		{"/docker/traefik.yaml", "/logs/traefik"},
+	}
+	for _, tt := range tests {
+		if got := archiveDir("/logs", "/docker", tt.file); got != tt.want {
+			t.Errorf("archiveDir(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
+
+func Test_rotatingWriter_reopen(t *testing.T) {
+	dir := t.TempDir()
+	started := time.Now().Add(-2 * time.Hour).UTC()
+	line := "web-1  | " + started.Format(time.RFC3339Nano) + " logged by an earlier run\n"
+	if err := os.WriteFile(filepath.Join(dir, currentLog), []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A restarted capture keeps the file's age, so it rotates by age.
+	w, err := openRotatingWriter(dir, CaptureConfig{MaxAge: Duration(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !w.opened.Equal(started) {
+		t.Errorf("opened = %v, want the first line's time %v", w.opened, started)
+	}
+	if err := w.writeLine("web-1  | " + time.Now().UTC().Format(time.RFC3339Nano) + " after the restart"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	files, err := archiveFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("archiveFiles() = %v, want the old file rotated", files)
+	}
+}
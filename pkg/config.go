@@ -0,0 +1,143 @@
+package ahab
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// Config is the user configuration, read from the file named by AHAB_CONFIG
+// or from ahab/config.json in the user config directory.
+type Config struct {
+	// Confirm lists the TUI actions that ask for confirmation first. An entry
+	// of the form "action:tag" only asks for stacks with that x-ahab tag.
+	Confirm []string `json:"confirm"`
+	// LogBuffer is how many log lines the TUI log viewer keeps per stream.
+	LogBuffer int `json:"log_buffer"`
+	// Capture configures ahab capture.
+	Capture CaptureConfig `json:"capture"`
+	// Alerts are log alert rules, on top of those in each stack's x-ahab.
+	Alerts []AlertRule `json:"alerts"`
+	// Notifier is where alerts are sent.
+	Notifier NotifierConfig `json:"notifier"`
+	// Shells maps "stack:service" or "service" to the command the TUI runs
+	// to open a shell in it, instead of detecting one.
+	Shells map[string]string `json:"shells"`
+	// Keys rebinds TUI actions, mapping an action to the keys that run it.
+	Keys map[string][]string `json:"keys"`
+	// Theme is the TUI's built-in color theme, "dark" (the default) or
+	// "light".
+	Theme string `json:"theme"`
+	// Colors overrides colors of the theme by name.
+	Colors map[string]string `json:"colors"`
+	// UpdateCheck is how often the TUI checks the registry for newer
+	// images. Zero, the default, disables the check.
+	UpdateCheck Duration `json:"update_check"`
+	// Mouse turns on mouse support in the TUI, so the summary bar's counts
+	// can be clicked. It stops the terminal from selecting text.
+	Mouse bool `json:"mouse"`
+}
+
+// CaptureConfig configures persistent log capture.
+type CaptureConfig struct {
+	// Stacks are the targets captured when ahab capture is given none.
+	Stacks []string `json:"stacks"`
+	// Dir holds one directory of logs per stack; defaults to logs in the
+	// state directory.
+	Dir string `json:"dir"`
+	// A stack's current log is rotated once it reaches MaxSizeMB or MaxAge,
+	// and only the newest Keep rotated logs are kept. Zero disables each limit.
+	MaxSizeMB int      `json:"max_size_mb"`
+	MaxAge    Duration `json:"max_age"`
+	Keep      int      `json:"keep"`
+}
+
+// Duration is a time.Duration written as a string such as "24h" in the config.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// DefaultConfig returns the configuration used when no config file exists.
+func DefaultConfig() Config {
+	return Config{
+		Confirm:   []string{"down", "stop:critical"},
+		LogBuffer: 2000,
+		Capture: CaptureConfig{
+			MaxSizeMB: 50,
+			MaxAge:    Duration(24 * time.Hour),
+			Keep:      14,
+		},
+	}
+}
+
+// ConfigPath returns the path of the config file.
+func ConfigPath() (string, error) {
+	if path := os.Getenv("AHAB_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ahab", "config.json"), nil
+}
+
+// StateDir returns the directory ahab keeps its state in, ahab under
+// $XDG_STATE_HOME or ~/.local/state.
+func StateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "ahab"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "ahab"), nil
+}
+
+// LoadConfig reads the config file. Settings missing from the file keep
+// their defaults, and a missing file yields DefaultConfig.
+func LoadConfig() (Config, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return DefaultConfig(), nil
+	}
+	return loadConfigFile(path)
+}
+
+func loadConfigFile(path string) (Config, error) {
+	cfg := DefaultConfig()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if cfg.LogBuffer < 1 {
+		return cfg, fmt.Errorf("%s: log_buffer must be at least 1", path)
+	}
+	for _, r := range cfg.Alerts {
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return cfg, fmt.Errorf("%s: alert %q: %w", path, r.label(), err)
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,58 @@
+package ahab
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_loadConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    Config
+		wantErr bool
+	}{
+		{
+			name: "missing file returns defaults",
+			path: "./testdata/config/missing.json",
+			want: DefaultConfig(),
+		},
+		{
+			name: "file overrides defaults",
+			path: "./testdata/config/config.json",
+			want: Config{
+				Confirm:   []string{"down", "restart:critical"},
+				LogBuffer: 2000,
+				Capture: CaptureConfig{
+					Stacks:    []string{"media"},
+					MaxSizeMB: 50,
+					MaxAge:    Duration(6 * time.Hour),
+					Keep:      14,
+				},
+				UpdateCheck: Duration(6 * time.Hour),
+				Mouse:       true,
+			},
+		},
+		{
+			name:    "invalid json returns error",
+			path:    "./testdata/config/invalid.json",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadConfigFile(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadConfigFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadConfigFile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package ahab
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	labelProject     = "com.docker.compose.project"
+	labelConfigFiles = "com.docker.compose.project.config_files"
+	labelService     = "com.docker.compose.service"
+)
+
+// ComposeEvent is a container lifecycle event for a compose-managed container.
+type ComposeEvent struct {
+	Action      string // start, stop, die or health_status
+	Health      string // healthy, unhealthy or starting for health_status events
+	Project     string
+	Service     string
+	Container   string
+	ConfigFiles []string
+	Time        time.Time
+}
+
+type dockerEvent struct {
+	Action string `json:"Action"`
+	Actor  struct {
+		ID         string            `json:"ID"`
+		Attributes map[string]string `json:"Attributes"`
+	} `json:"Actor"`
+	Time     int64 `json:"time"`
+	TimeNano int64 `json:"timeNano"`
+}
+
+// parseComposeEvent decodes one line of `docker events --format '{{json .}}'`.
+func parseComposeEvent(line []byte) (ComposeEvent, bool) {
+	var de dockerEvent
+	if err := json.Unmarshal(line, &de); err != nil {
+		return ComposeEvent{}, false
+	}
+	attrs := de.Actor.Attributes
+	project := attrs[labelProject]
+	if project == "" {
+		return ComposeEvent{}, false
+	}
+	ev := ComposeEvent{
+		Action:    de.Action,
+		Project:   project,
+		Service:   attrs[labelService],
+		Container: attrs["name"],
+		Time:      time.Unix(de.Time, 0),
+	}
+	if action, health, ok := strings.Cut(de.Action, ":"); ok {
+		ev.Action = action
+		ev.Health = strings.TrimSpace(health)
+	}
+	if de.TimeNano != 0 {
+		ev.Time = time.Unix(0, de.TimeNano)
+	}
+	if ev.Container == "" {
+		ev.Container = de.Actor.ID
+	}
+	for _, f := range strings.Split(attrs[labelConfigFiles], ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			ev.ConfigFiles = append(ev.ConfigFiles, f)
+		}
+	}
+	return ev, true
+}
+
+// WatchComposeEvents streams start, stop, die and health events for
+// compose-managed containers. The channel is closed when ctx is cancelled
+// or the docker events process exits.
+func WatchComposeEvents(ctx context.Context) (<-chan ComposeEvent, error) {
+	cmd := exec.CommandContext(ctx, "docker", "events",
+		"--filter", "type=container",
+		"--filter", "label="+labelProject,
+		"--filter", "event=start",
+		"--filter", "event=stop",
+		"--filter", "event=die",
+		"--filter", "event=health_status",
+		"--format", "{{json .}}",
+	)
+	cmd.Stderr = io.Discard
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan ComposeEvent)
+	go func() {
+		defer close(ch)
+		defer func() { _ = cmd.Wait() }()
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			ev, ok := parseComposeEvent(scanner.Bytes())
+			if !ok {
+				continue
+			}
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
@@ -0,0 +1,68 @@
+package ahab
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_parseComposeEvent(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   ComposeEvent
+		wantOK bool
+	}{
+		{
+			name: "container die",
+			line: `{"Type":"container","Action":"die","Actor":{"ID":"abc","Attributes":{"name":"web-1","com.docker.compose.project":"web","com.docker.compose.service":"app","com.docker.compose.project.config_files":"/docker/web/compose.yaml"}},"time":1714557600,"timeNano":1714557600123456789}`,
+			want: ComposeEvent{
+				Action:      "die",
+				Project:     "web",
+				Service:     "app",
+				Container:   "web-1",
+				ConfigFiles: []string{"/docker/web/compose.yaml"},
+				Time:        time.Unix(1714557600, 123456789),
+			},
+			wantOK: true,
+		},
+		{
+			name: "health status",
+			line: `{"Action":"health_status: unhealthy","Actor":{"ID":"abc","Attributes":{"com.docker.compose.project":"db","com.docker.compose.project.config_files":"/a.yaml,/b.yaml"}},"time":1714557601}`,
+			want: ComposeEvent{
+				Action:      "health_status",
+				Health:      "unhealthy",
+				Project:     "db",
+				Container:   "abc",
+				ConfigFiles: []string{"/a.yaml", "/b.yaml"},
+				Time:        time.Unix(1714557601, 0),
+			},
+			wantOK: true,
+		},
+		{
+			name:   "not a compose container",
+			line:   `{"Action":"start","Actor":{"ID":"abc","Attributes":{"name":"x"}},"time":0}`,
+			wantOK: false,
+		},
+		{
+			name:   "invalid json",
+			line:   `not json`,
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseComposeEvent([]byte(tt.line))
+			if ok != tt.wantOK {
+				t.Fatalf("parseComposeEvent() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseComposeEvent() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
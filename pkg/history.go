@@ -0,0 +1,140 @@
+package ahab
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// HistoryEntry is one action the TUI ran on a stack.
+type HistoryEntry struct {
+	File     string        `json:"file"`
+	Services []string      `json:"services,omitempty"`
+	Action   string        `json:"action"`
+	Args     []string      `json:"args"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	// Result is "done", "failed" or "cancelled".
+	Result string   `json:"result"`
+	Error  string   `json:"error,omitempty"`
+	Output []string `json:"output,omitempty"`
+}
+
+// Target returns the target the action ran on.
+func (e HistoryEntry) Target() Target {
+	return Target{File: e.File, Services: e.Services}
+}
+
+// HistoryPath returns the file the action history is kept in.
+func HistoryPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.jsonl"), nil
+}
+
+// historyLock is the lock file that keeps appends from landing while a
+// history file is being trimmed.
+func historyLock(path string) string {
+	return path + ".lock"
+}
+
+// AppendHistory adds an entry to the end of a history file.
+func AppendHistory(path string, e HistoryEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	unlock, err := lockFile(historyLock(path))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadHistory returns the last limit entries of a history file, oldest
+// first, and trims the file to them. Lines that don't parse are skipped, and
+// a missing file has no entries.
+func LoadHistory(path string, limit int) ([]HistoryEntry, error) {
+	entries, err := readHistory(path)
+	if err != nil || len(entries) <= limit {
+		return entries, err
+	}
+	// Trim under the lock, rereading so that entries appended meanwhile,
+	// by this or another ahab, are kept.
+	unlock, err := lockFile(historyLock(path))
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if entries, err = readHistory(path); err != nil || len(entries) <= limit {
+		return entries, err
+	}
+	entries = entries[len(entries)-limit:]
+	return entries, rewriteHistory(path, entries)
+}
+
+// readHistory reads every entry of a history file.
+func readHistory(path string) ([]HistoryEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	const maxCapacity = 4 * 1024 * 1024 // 4MB, for entries with a lot of output
+	scanner.Buffer(make([]byte, 64*1024), maxCapacity)
+	for scanner.Scan() {
+		var e HistoryEntry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// rewriteHistory replaces a history file with entries.
+func rewriteHistory(path string, entries []HistoryEntry) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".history-*")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,61 @@
+package ahab
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "history.jsonl")
+	if entries, err := LoadHistory(path, 10); err != nil || len(entries) != 0 {
+		t.Fatalf("LoadHistory() on missing file = %v, %v", entries, err)
+	}
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, action := range []string{"start", "stop", "down"} {
+		e := HistoryEntry{
+			File:     "/docker/media/compose.yaml",
+			Action:   action,
+			Args:     []string{action},
+			Start:    start.Add(time.Duration(i) * time.Minute),
+			Duration: 2 * time.Second,
+			Result:   "done",
+			Output:   []string{"Container media-plex-1  " + action},
+		}
+		if err := AppendHistory(path, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("{not json\n")
+	f.Close()
+
+	entries, err := LoadHistory(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[0].Action != "start" || !entries[2].Start.Equal(start.Add(2*time.Minute)) {
+		t.Fatalf("LoadHistory() = %+v", entries)
+	}
+
+	entries, err = LoadHistory(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+	if want := []string{"stop", "down"}; !reflect.DeepEqual(actions, want) {
+		t.Errorf("LoadHistory() with limit = %v, want %v", actions, want)
+	}
+	if again, _ := LoadHistory(path, 10); len(again) != 2 {
+		t.Errorf("LoadHistory() didn't trim the file, got %d entries", len(again))
+	}
+}
@@ -0,0 +1,20 @@
+package ahab
+
+import (
+	"bufio"
+	"io"
+)
+
+// maxLineSize is the longest line ReadLines accepts.
+const maxLineSize = 512 * 1024 // 512KB
+
+// ReadLines passes each line read from r to emit, until r ends or a line
+// is longer than maxLineSize.
+func ReadLines(r io.Reader, emit func(string)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		emit(scanner.Text())
+	}
+	return scanner.Err()
+}
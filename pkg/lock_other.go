@@ -0,0 +1,8 @@
+//go:build !unix
+
+package ahab
+
+// lockFile is a no-op where flock isn't available.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
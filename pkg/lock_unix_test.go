@@ -0,0 +1,60 @@
+//go:build unix
+
+package ahab
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLoadHistory_trimKeepsAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	for i := range 10 {
+		if err := AppendHistory(path, HistoryEntry{Action: strconv.Itoa(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Hold the lock as another ahab appending would, so the trim waits.
+	unlock, err := lockFile(historyLock(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := make(chan []HistoryEntry)
+	go func() {
+		entries, err := LoadHistory(path, 5)
+		if err != nil {
+			t.Error(err)
+		}
+		loaded <- entries
+	}()
+	select {
+	case <-loaded:
+		t.Fatal("LoadHistory trimmed without taking the lock")
+	case <-time.After(100 * time.Millisecond):
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"action":"10"}` + "\n")
+	f.Close()
+	unlock()
+	<-loaded
+
+	entries, err := LoadHistory(path, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, e := range entries {
+		actions = append(actions, e.Action)
+	}
+	if want := []string{"6", "7", "8", "9", "10"}; !reflect.DeepEqual(actions, want) {
+		t.Errorf("history after trimming = %v, want %v", actions, want)
+	}
+}
@@ -0,0 +1,389 @@
+package ahab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// LogLine is a line of "docker compose logs --timestamps" output.
+type LogLine struct {
+	Prefix string // "web-1  | ", empty if absent
+	Time   time.Time
+	Text   string
+}
+
+// ParseLogLine splits a line into its service prefix, timestamp and text.
+// Lines that don't have a timestamp keep all of their text.
+func ParseLogLine(line string) LogLine {
+	var l LogLine
+	if i := strings.Index(line, " | "); i >= 0 {
+		l.Prefix, line = line[:i+3], line[i+3:]
+	}
+	if field, rest, ok := strings.Cut(line, " "); ok {
+		if t, err := time.Parse(time.RFC3339Nano, field); err == nil {
+			l.Time = t
+			line = rest
+		}
+	}
+	l.Text = line
+	return l
+}
+
+var replicaSuffix = regexp.MustCompile(`-\d+$`)
+
+// Service returns the service named by the line's prefix: "web-1  | " is web.
+func (l LogLine) Service() string {
+	name := strings.TrimSpace(strings.TrimSuffix(l.Prefix, " | "))
+	return replicaSuffix.ReplaceAllString(name, "")
+}
+
+// LogsOptions configures Logs.
+type LogsOptions struct {
+	Follow bool
+	Since  string // passed to docker compose logs --since, e.g. "10m"
+	Grep   string // only lines whose text matches this regular expression
+	// Archive searches the logs saved by ahab capture instead of Docker's.
+	Archive bool
+}
+
+// mergeWindow is how long followed lines are held back so that lines from
+// different stacks come out in timestamp order.
+const mergeWindow = 500 * time.Millisecond
+
+// maxPending caps how many lines mergeLogs holds back. Past it, the oldest
+// half is written even though an older line might still arrive.
+const maxPending = 20000
+
+// stackLine is a log line tagged with the stack and service it came from,
+// or, with done set, the end of a source's lines.
+type stackLine struct {
+	source int // which of the merged streams the line came from
+	done   bool
+	name   string
+	line   LogLine
+	at     time.Time // sort key: the line's timestamp, or when it arrived
+	seen   time.Time
+}
+
+// Logs prints the logs of the targeted stacks, or every stack, merged in
+// timestamp order and prefixed with stack and service.
+func Logs(opts LogsOptions, targets ...string) error {
+	var grep *regexp.Regexp
+	if opts.Grep != "" {
+		var err error
+		if grep, err = regexp.Compile(opts.Grep); err != nil {
+			return fmt.Errorf("invalid --grep: %w", err)
+		}
+	}
+	root, resolved, err := resolveDiscovered(targets)
+	if err != nil {
+		return err
+	}
+	if opts.Archive {
+		if opts.Follow {
+			return errors.New("--follow can't be used with --archive")
+		}
+		return searchArchive(os.Stdout, root, resolved, opts, grep)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return streamLogs(ctx, os.Stdout, root, resolved, opts, grep)
+}
+
+// resolveDiscovered resolves targets against the non-ignored compose files
+// under DOCKER_DIR, which it also returns.
+func resolveDiscovered(targets []string) (string, []Target, error) {
+	root, err := getDockerDir()
+	if err != nil {
+		return "", nil, err
+	}
+	infos, err := FindComposeFilesForTUI()
+	if err != nil {
+		return "", nil, err
+	}
+	files := make([]string, len(infos))
+	for i, info := range infos {
+		files[i] = info.Path
+	}
+	resolved, err := resolveTargets(root, files, targets)
+	return root, resolved, err
+}
+
+// streamLogs runs docker compose logs on every target at once and writes
+// the merged lines to w.
+func streamLogs(ctx context.Context, w io.Writer, root string, targets []Target, opts LogsOptions, grep *regexp.Regexp) error {
+	lines := make(chan stackLine, 256)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+	for i, target := range targets {
+		wg.Add(1)
+		go func(t Target) {
+			defer wg.Done()
+			err := readLogs(ctx, t, logsArgs(opts), lineSender(lines, i, StackName(root, t.File), grep, time.Time{}))
+			if err != nil && ctx.Err() == nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", t, err))
+				mu.Unlock()
+			}
+			lines <- stackLine{source: i, done: true}
+		}(target)
+	}
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
+
+	window := time.Duration(-1)
+	if opts.Follow {
+		window = mergeWindow
+	}
+	mergeLogs(w, lines, len(targets), window)
+	return errors.Join(errs...)
+}
+
+// lineSender returns a function that sends a source's lines to a channel,
+// skipping lines that don't match grep or are older than since.
+func lineSender(lines chan<- stackLine, source int, stack string, grep *regexp.Regexp, since time.Time) func(LogLine) {
+	return func(l LogLine) {
+		if grep != nil && !grep.MatchString(l.Text) || !since.IsZero() && l.Time.Before(since) {
+			return
+		}
+		name := stack
+		if s := l.Service(); s != "" {
+			name += "/" + s
+		}
+		lines <- stackLine{source: source, name: name, line: l}
+	}
+}
+
+// searchArchive writes the captured logs of the targets that match the
+// options, in timestamp order.
+func searchArchive(w io.Writer, root string, targets []Target, opts LogsOptions, grep *regexp.Regexp) error {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	dir, err := captureDir(cfg.Capture)
+	if err != nil {
+		return err
+	}
+	var since time.Time
+	if opts.Since != "" {
+		if since, err = parseSince(opts.Since, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	// Each stack's archive is in time order, so reading them side by side
+	// lets the merge write lines as it goes.
+	lines := make(chan stackLine, 256)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+	for i, target := range targets {
+		wg.Add(1)
+		go func(t Target) {
+			defer wg.Done()
+			defer func() { lines <- stackLine{source: i, done: true} }()
+			stack := StackName(root, t.File)
+			files, err := archiveFiles(archiveDir(dir, root, t.File))
+			send := lineSender(lines, i, stack, grep, since)
+			for _, f := range files {
+				if err != nil {
+					break
+				}
+				err = readArchive(f, func(l LogLine) {
+					if len(t.Services) == 0 || slices.Contains(t.Services, l.Service()) {
+						send(l)
+					}
+				})
+			}
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(target)
+	}
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
+	mergeLogs(w, lines, len(targets), -1)
+	return errors.Join(errs...)
+}
+
+// parseSince parses a --since value: a duration before now, an RFC 3339
+// timestamp or a date.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: want a duration such as 10m or a timestamp", s)
+}
+
+func logsArgs(opts LogsOptions) []string {
+	args := []string{"logs", "--timestamps"}
+	if opts.Follow {
+		args = append(args, "--follow")
+	}
+	if opts.Since != "" {
+		args = append(args, "--since", opts.Since)
+	}
+	return args
+}
+
+// readLogs runs docker compose with args on a target and passes each line
+// of output to emit.
+func readLogs(ctx context.Context, t Target, args []string, emit func(LogLine)) error {
+	cmdArgs := append([]string{"compose", "-f", t.File}, args...)
+	cmdArgs = append(cmdArgs, t.Services...)
+	cmd := exec.CommandContext(ctx, "docker", cmdArgs...)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	err = ReadLines(stdout, func(line string) { emit(ParseLogLine(line)) })
+	if err != nil {
+		_ = cmd.Wait()
+		return err
+	}
+	return cmd.Wait()
+}
+
+// mergeLogs writes the lines of sources streams in timestamp order. With a
+// negative window, lines are written once every unfinished stream has sent
+// a line at least as new; otherwise lines are written once they have waited
+// for the window, along with any older lines that arrived later. Either way
+// at most maxPending lines are held back.
+func mergeLogs(w io.Writer, in <-chan stackLine, sources int, window time.Duration) {
+	var pending []stackLine
+	width := 0
+	sortPending := func() {
+		sort.SliceStable(pending, func(i, j int) bool { return pending[i].at.Before(pending[j].at) })
+	}
+	write := func(n int) {
+		for _, l := range pending[:n] {
+			width = max(width, len(l.name))
+			fmt.Fprintf(w, "%-*s | %s\n", width, l.name, l.line.Text)
+		}
+		pending = pending[n:]
+	}
+
+	// latest is the newest line of each stack/service, and owner the source
+	// it comes from.
+	latest := make(map[string]time.Time)
+	owner := make(map[string]int)
+	started := make(map[int]bool)
+	done := make(map[int]bool)
+	// writeSettled writes the lines that no unfinished source can still
+	// send an older line than.
+	writeSettled := func() {
+		for s := range sources {
+			if !started[s] && !done[s] {
+				return
+			}
+		}
+		var mark time.Time
+		open := false
+		for name, at := range latest {
+			if !done[owner[name]] && (!open || at.Before(mark)) {
+				mark, open = at, true
+			}
+		}
+		sortPending()
+		n := len(pending)
+		if open {
+			n = sort.Search(len(pending), func(i int) bool { return pending[i].at.After(mark) })
+		}
+		write(n)
+	}
+
+	var tick <-chan time.Time
+	if window >= 0 {
+		ticker := time.NewTicker(window / 2)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	received := 0
+	for {
+		select {
+		case l, ok := <-in:
+			if !ok {
+				sortPending()
+				write(len(pending))
+				return
+			}
+			if l.done {
+				done[l.source] = true
+				if window < 0 {
+					writeSettled()
+				}
+				continue
+			}
+			l.seen = time.Now()
+			l.at = l.line.Time
+			if l.at.IsZero() {
+				l.at = l.seen
+			}
+			pending = append(pending, l)
+			started[l.source] = true
+			owner[l.name] = l.source
+			if l.at.After(latest[l.name]) {
+				latest[l.name] = l.at
+			}
+			if received++; window < 0 && received%1024 == 0 {
+				writeSettled()
+			}
+			if len(pending) > maxPending {
+				sortPending()
+				write(len(pending) / 2)
+			}
+		case now := <-tick:
+			sortPending()
+			n := 0
+			for i, l := range pending {
+				if now.Sub(l.seen) >= window {
+					n = i + 1
+				}
+			}
+			write(n)
+		}
+	}
+}
+
+// StackName names a stack by its directory relative to root, or by its file
+// name when it sits directly in root.
+func StackName(root, file string) string {
+	rel, err := filepath.Rel(root, file)
+	if err != nil {
+		return file
+	}
+	if dir := filepath.Dir(rel); dir != "." {
+		return filepath.ToSlash(dir)
+	}
+	return rel
+}
@@ -0,0 +1,120 @@
+package ahab
+
+import (
+	"bytes"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseLogLine(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC)
+	tests := []struct {
+		line        string
+		want        LogLine
+		wantService string
+	}{
+		{"web-1  | 2024-05-01T10:00:00.123456789Z hello world", LogLine{Prefix: "web-1  | ", Time: ts, Text: "hello world"}, "web"},
+		{"2024-05-01T10:00:00.123456789Z hello", LogLine{Time: ts, Text: "hello"}, ""},
+		{"db-replica-12 | no timestamp here", LogLine{Prefix: "db-replica-12 | ", Text: "no timestamp here"}, "db-replica"},
+		{"[log stream error: boom]", LogLine{Text: "[log stream error: boom]"}, ""},
+	}
+	for _, tt := range tests {
+		got := ParseLogLine(tt.line)
+		if got != tt.want {
+			t.Errorf("ParseLogLine(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+		if s := got.Service(); s != tt.wantService {
+			t.Errorf("ParseLogLine(%q).Service() = %q, want %q", tt.line, s, tt.wantService)
+		}
+	}
+}
+
+func Test_mergeLogs(t *testing.T) {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	in := make(chan stackLine, 3)
+	in <- stackLine{name: "media/plex", line: LogLine{Time: base.Add(2 * time.Second), Text: "third"}}
+	in <- stackLine{name: "web/nginx", line: LogLine{Time: base, Text: "first"}}
+	in <- stackLine{name: "media/plex", line: LogLine{Time: base.Add(time.Second), Text: "second"}}
+	close(in)
+
+	var out bytes.Buffer
+	mergeLogs(&out, in, 1, -1)
+	want := "web/nginx | first\nmedia/plex | second\nmedia/plex | third\n"
+	if out.String() != want {
+		t.Errorf("mergeLogs() wrote\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+// lineCounter counts the lines written to it.
+type lineCounter struct{ n atomic.Int64 }
+
+func (c *lineCounter) Write(p []byte) (int, error) {
+	c.n.Add(int64(bytes.Count(p, []byte("\n"))))
+	return len(p), nil
+}
+
+// waitLines waits for c to have counted at least n lines.
+func waitLines(t *testing.T, c *lineCounter, n int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for c.n.Load() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("wrote %d lines before the input ended, want %d", c.n.Load(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func Test_mergeLogs_streams(t *testing.T) {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	// Lines no unfinished source can precede are written right away.
+	in := make(chan stackLine)
+	out := &lineCounter{}
+	done := make(chan struct{})
+	go func() {
+		mergeLogs(out, in, 2, -1)
+		close(done)
+	}()
+	in <- stackLine{source: 0, name: "media/plex", line: LogLine{Time: base, Text: "a"}}
+	in <- stackLine{source: 1, name: "web/nginx", line: LogLine{Time: base.Add(time.Second), Text: "b"}}
+	in <- stackLine{source: 0, done: true}
+	waitLines(t, out, 2)
+	close(in)
+	<-done
+
+	// A source that never sends doesn't hold back more than maxPending lines.
+	in = make(chan stackLine)
+	out = &lineCounter{}
+	done = make(chan struct{})
+	go func() {
+		mergeLogs(out, in, 2, -1)
+		close(done)
+	}()
+	for i := range maxPending + 1 {
+		in <- stackLine{source: 0, name: "media/plex", line: LogLine{Time: base.Add(time.Duration(i) * time.Millisecond)}}
+	}
+	waitLines(t, out, maxPending/2)
+	close(in)
+	<-done
+	if n := out.n.Load(); n != maxPending+1 {
+		t.Errorf("wrote %d lines, want %d", n, maxPending+1)
+	}
+}
+
+func TestStackName(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"/docker/media/compose.yaml", "media"},
+		{"/docker/apps/web/compose.yaml", "apps/web"},
+		{"/docker/traefik.yaml", "traefik.yaml"},
+	}
+	for _, tt := range tests {
+		if got := StackName("/docker", tt.file); got != tt.want {
+			t.Errorf("StackName(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package ahab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// ComposeMeta describes a compose file as resolved by docker compose config.
+type ComposeMeta struct {
+	Project  string
+	Services []string
+	// Tags come from the top-level x-ahab.tags extension.
+	Tags []string
+	// Alerts come from x-ahab.alerts.
+	Alerts []AlertRule
+}
+
+type composeConfig struct {
+	Name     string                     `json:"name"`
+	Services map[string]json.RawMessage `json:"services"`
+	Ahab     struct {
+		Tags   []string    `json:"tags"`
+		Alerts []AlertRule `json:"alerts"`
+	} `json:"x-ahab"`
+}
+
+// GetComposeMeta resolves a compose file's project name, services and ahab tags.
+func GetComposeMeta(ctx context.Context, file string) (ComposeMeta, error) {
+	out, err := exec.CommandContext(ctx, "docker", "compose", "-f", file, "config", "--format", "json").Output()
+	if err != nil {
+		return ComposeMeta{}, fmt.Errorf("docker compose config: %w", err)
+	}
+	return parseComposeMeta(out)
+}
+
+// ComposeConfig returns a compose file as resolved by docker compose config,
+// with interpolation, extends and profiles applied. When the file is invalid,
+// the error holds Compose's message.
+func ComposeConfig(ctx context.Context, file string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", "compose", "-f", file, "config")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", fmt.Errorf("docker compose config: %w", err)
+	}
+	return string(out), nil
+}
+
+func parseComposeMeta(data []byte) (ComposeMeta, error) {
+	var cfg composeConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return ComposeMeta{}, err
+	}
+	meta := ComposeMeta{Project: cfg.Name, Tags: cfg.Ahab.Tags, Alerts: cfg.Ahab.Alerts}
+	for name := range cfg.Services {
+		meta.Services = append(meta.Services, name)
+	}
+	sort.Strings(meta.Services)
+	return meta, nil
+}
+
+// HasTag reports whether the compose file is tagged with tag in x-ahab.
+func (c ComposeMeta) HasTag(tag string) bool {
+	for _, t := range c.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package ahab
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_parseComposeMeta(t *testing.T) {
+	data := []byte(`{
+		"name": "media",
+		"services": {"sonarr": {"image": "x"}, "plex": {"image": "y"}},
+		"x-ahab": {"tags": ["critical", "media"], "alerts": [{"pattern": "database is locked", "services": ["sonarr"], "cooldown": "10m"}]}
+	}`)
+	got, err := parseComposeMeta(data)
+	if err != nil {
+		t.Fatalf("parseComposeMeta() error = %v", err)
+	}
+	want := ComposeMeta{
+		Project:  "media",
+		Services: []string{"plex", "sonarr"},
+		Tags:     []string{"critical", "media"},
+		Alerts: []AlertRule{
+			{Pattern: "database is locked", Services: []string{"sonarr"}, Cooldown: Duration(10 * time.Minute)},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseComposeMeta() = %+v, want %+v", got, want)
+	}
+	if !got.HasTag("critical") || got.HasTag("dev") {
+		t.Errorf("HasTag() mismatch for tags %v", got.Tags)
+	}
+
+	if _, err := parseComposeMeta([]byte("not json")); err == nil {
+		t.Errorf("parseComposeMeta() expected error for invalid json")
+	}
+}
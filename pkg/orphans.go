@@ -0,0 +1,136 @@
+package ahab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ComposeProject is a compose project known to the Docker daemon.
+type ComposeProject struct {
+	Name        string
+	Status      string
+	ConfigFiles []string
+}
+
+type composeLsEntry struct {
+	Name        string `json:"Name"`
+	Status      string `json:"Status"`
+	ConfigFiles string `json:"ConfigFiles"`
+}
+
+// ListComposeProjects returns the running compose projects reported by docker compose ls.
+func ListComposeProjects(ctx context.Context) ([]ComposeProject, error) {
+	out, err := exec.CommandContext(ctx, "docker", "compose", "ls", "--format", "json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker compose ls: %w", err)
+	}
+	var entries []composeLsEntry
+	if err := json.Unmarshal(out, &entries); err != nil {
+		return nil, fmt.Errorf("docker compose ls: %w", err)
+	}
+	projects := make([]ComposeProject, 0, len(entries))
+	for _, e := range entries {
+		p := ComposeProject{Name: e.Name, Status: e.Status}
+		for _, f := range strings.Split(e.ConfigFiles, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				p.ConfigFiles = append(p.ConfigFiles, f)
+			}
+		}
+		projects = append(projects, p)
+	}
+	return projects, nil
+}
+
+// FindOrphans returns running compose projects whose config files are no
+// longer among the YAML files discovered in DOCKER_DIR. Files excluded by
+// .ahabignore still count as discovered, so ignoring a stack never makes it
+// an orphan.
+func FindOrphans(ctx context.Context) ([]ComposeProject, error) {
+	dir, err := getDockerDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := findYAMLFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := ListComposeProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return findOrphans(projects, files), nil
+}
+
+func findOrphans(projects []ComposeProject, files []string) []ComposeProject {
+	known := make(map[string]struct{}, len(files))
+	for _, f := range files {
+		known[absPath(f)] = struct{}{}
+	}
+	var orphans []ComposeProject
+	for _, p := range projects {
+		found := false
+		for _, f := range p.ConfigFiles {
+			if _, ok := known[absPath(f)]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			orphans = append(orphans, p)
+		}
+	}
+	return orphans
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// DownProject runs docker compose down for a project by name, without its config files.
+func DownProject(ctx context.Context, stdout, stderr io.Writer, project string, args ...string) error {
+	cmdArgs := append([]string{"compose", "-p", project, "down"}, args...)
+	cmd := exec.CommandContext(ctx, "docker", cmdArgs...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// ListOrphans prints orphaned compose projects and optionally takes them down.
+func ListOrphans(down bool) error {
+	ctx := context.Background()
+	orphans, err := FindOrphans(ctx)
+	if err != nil {
+		return err
+	}
+	if len(orphans) == 0 {
+		fmt.Println("No orphaned compose projects found.")
+		return nil
+	}
+
+	fmt.Println("Orphaned compose projects:")
+	for _, p := range orphans {
+		fmt.Printf("  %s (%s) %s\n", p.Name, p.Status, strings.Join(p.ConfigFiles, ","))
+	}
+	if !down {
+		return nil
+	}
+
+	var errs []error
+	for _, p := range orphans {
+		fmt.Printf("Running: docker compose -p %s down\n", p.Name)
+		if err := DownProject(ctx, os.Stdout, os.Stderr, p.Name); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
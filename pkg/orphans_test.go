@@ -0,0 +1,28 @@
+package ahab
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_findOrphans(t *testing.T) {
+	projects := []ComposeProject{
+		{Name: "web", ConfigFiles: []string{"/docker/web/compose.yaml"}},
+		{Name: "gone", ConfigFiles: []string{"/docker/old/compose.yaml"}},
+		{Name: "multi", ConfigFiles: []string{"/elsewhere/base.yaml", "/docker/multi/override.yaml"}},
+		{Name: "nofiles"},
+	}
+	files := []string{
+		"/docker/web/compose.yaml",
+		"/docker/multi/override.yaml",
+	}
+
+	var got []string
+	for _, p := range findOrphans(projects, files) {
+		got = append(got, p.Name)
+	}
+	want := []string{"gone", "nofiles"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findOrphans() = %v, want %v", got, want)
+	}
+}
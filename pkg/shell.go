@@ -0,0 +1,47 @@
+package ahab
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// shellCandidates are tried in order when a service has no configured shell.
+var shellCandidates = []string{"sh", "bash", "/bin/sh"}
+
+// DetectShell returns the first shell that runs in a service's container.
+func DetectShell(ctx context.Context, file, service string) (string, error) {
+	var first []byte
+	for _, sh := range shellCandidates {
+		out, err := exec.CommandContext(ctx, "docker", "compose", "-f", file, "exec", "-T", service, sh, "-c", "exit 0").CombinedOutput()
+		if err == nil {
+			return sh, nil
+		}
+		if first == nil {
+			first = bytes.TrimSpace(out)
+		}
+	}
+	if len(first) > 0 {
+		return "", fmt.Errorf("no shell found in %s: %s", service, first)
+	}
+	return "", fmt.Errorf("no shell found in %s (tried %s)", service, strings.Join(shellCandidates, ", "))
+}
+
+// ShellFor returns the configured shell of a service, or "" if there is
+// none. Keys of Shells are "stack:service" or just "service"; the more
+// specific key wins.
+func (c Config) ShellFor(root, file, service string) string {
+	shell := ""
+	for key, sh := range c.Shells {
+		stack, svc := splitTarget(key)
+		switch {
+		case svc == "" && stack == service && shell == "":
+			shell = sh
+		case svc == service && matchesStack(root, file, stack):
+			return sh
+		}
+	}
+	return shell
+}
@@ -0,0 +1,26 @@
+package ahab
+
+import "testing"
+
+func TestConfig_ShellFor(t *testing.T) {
+	cfg := Config{Shells: map[string]string{
+		"postgres":       "psql -U postgres",
+		"media:postgres": "bash",
+		"web":            "ash",
+	}}
+	tests := []struct {
+		file    string
+		service string
+		want    string
+	}{
+		{"/docker/media/compose.yaml", "postgres", "bash"},
+		{"/docker/apps/compose.yaml", "postgres", "psql -U postgres"},
+		{"/docker/apps/compose.yaml", "web", "ash"},
+		{"/docker/apps/compose.yaml", "redis", ""},
+	}
+	for _, tt := range tests {
+		if got := cfg.ShellFor("/docker", tt.file, tt.service); got != tt.want {
+			t.Errorf("ShellFor(%q, %q) = %q, want %q", tt.file, tt.service, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package ahab
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ContainerStats is a single docker stats sample for a compose container.
+type ContainerStats struct {
+	Name        string
+	Service     string
+	ConfigFiles []string
+	CPUPercent  float64
+	MemUsage    uint64
+	MemLimit    uint64
+	NetRx       uint64
+	NetTx       uint64
+	BlockRead   uint64
+	BlockWrite  uint64
+}
+
+type dockerStats struct {
+	Name     string `json:"Name"`
+	CPUPerc  string `json:"CPUPerc"`
+	MemUsage string `json:"MemUsage"`
+	NetIO    string `json:"NetIO"`
+	BlockIO  string `json:"BlockIO"`
+}
+
+// GetComposeStats samples docker stats once for every running compose container.
+func GetComposeStats(ctx context.Context) ([]ContainerStats, error) {
+	ps := exec.CommandContext(ctx, "docker", "ps",
+		"--filter", "label="+labelProject,
+		"--format", `{{.Names}}\t{{.Label "`+labelService+`"}}\t{{.Label "`+labelConfigFiles+`"}}`,
+	)
+	out, err := ps.Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker ps: %w", err)
+	}
+
+	byName := make(map[string]ContainerStats)
+	var names []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		cs := ContainerStats{Name: fields[0], Service: fields[1]}
+		for _, f := range strings.Split(fields[2], ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				cs.ConfigFiles = append(cs.ConfigFiles, f)
+			}
+		}
+		byName[cs.Name] = cs
+		names = append(names, cs.Name)
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	args := append([]string{"stats", "--no-stream", "--format", "{{json .}}"}, names...)
+	out, err = exec.CommandContext(ctx, "docker", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker stats: %w", err)
+	}
+
+	var result []ContainerStats
+	scanner = bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		var ds dockerStats
+		if err := json.Unmarshal(scanner.Bytes(), &ds); err != nil {
+			continue
+		}
+		cs, ok := byName[ds.Name]
+		if !ok {
+			continue
+		}
+		cs.CPUPercent, _ = strconv.ParseFloat(strings.TrimSuffix(ds.CPUPerc, "%"), 64)
+		cs.MemUsage, cs.MemLimit = parseSizePair(ds.MemUsage)
+		cs.NetRx, cs.NetTx = parseSizePair(ds.NetIO)
+		cs.BlockRead, cs.BlockWrite = parseSizePair(ds.BlockIO)
+		result = append(result, cs)
+	}
+	return result, nil
+}
+
+// parseSizePair parses docker's "used / total" size columns.
+func parseSizePair(s string) (uint64, uint64) {
+	a, b, _ := strings.Cut(s, "/")
+	return parseSize(a), parseSize(b)
+}
+
+var sizeUnits = []struct {
+	suffix string
+	mult   float64
+}{
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"TiB", 1 << 40},
+	{"kB", 1e3},
+	{"KB", 1e3},
+	{"MB", 1e6},
+	{"GB", 1e9},
+	{"TB", 1e12},
+	{"B", 1},
+}
+
+// parseSize parses sizes such as "1.5MiB" or "20kB" as printed by docker stats.
+func parseSize(s string) uint64 {
+	s = strings.TrimSpace(s)
+	for _, u := range sizeUnits {
+		if num, ok := strings.CutSuffix(s, u.suffix); ok {
+			v, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+			if err != nil {
+				return 0
+			}
+			return uint64(v * u.mult)
+		}
+	}
+	return 0
+}
+
+// FormatBytes renders a byte count using binary units, e.g. "312.4MiB".
+func FormatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,50 @@
+package ahab
+
+import "testing"
+
+func Test_parseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want uint64
+	}{
+		{"0B", 0},
+		{"512B", 512},
+		{"1.5KiB", 1536},
+		{"2MiB", 2 << 20},
+		{"1GiB", 1 << 30},
+		{"20kB", 20000},
+		{"3.5MB", 3500000},
+		{" 1GB ", 1000000000},
+		{"--", 0},
+	}
+	for _, tt := range tests {
+		if got := parseSize(tt.in); got != tt.want {
+			t.Errorf("parseSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func Test_parseSizePair(t *testing.T) {
+	used, total := parseSizePair("10MiB / 1GiB")
+	if used != 10<<20 || total != 1<<30 {
+		t.Errorf("parseSizePair() = %d, %d, want %d, %d", used, total, 10<<20, 1<<30)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		in   uint64
+		want string
+	}{
+		{0, "0B"},
+		{1023, "1023B"},
+		{1536, "1.5KiB"},
+		{10 << 20, "10.0MiB"},
+		{3 << 30, "3.0GiB"},
+	}
+	for _, tt := range tests {
+		if got := FormatBytes(tt.in); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
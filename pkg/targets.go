@@ -0,0 +1,81 @@
+package ahab
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// splitTarget splits a "stack:service" argument. A colon followed by a path
+// separator is treated as part of the stack, not a service.
+func splitTarget(arg string) (stack, service string) {
+	i := strings.LastIndex(arg, ":")
+	if i < 0 || i == len(arg)-1 || strings.ContainsAny(arg[i+1:], `/\`) {
+		return arg, ""
+	}
+	return arg[:i], arg[i+1:]
+}
+
+// matchesStack reports whether a stack argument names file. A stack can be
+// given as the file's path, its path relative to root, its directory relative
+// to root, or its directory name.
+func matchesStack(root, file, stack string) bool {
+	stack = filepath.Clean(stack)
+	if stack == filepath.Clean(file) {
+		return true
+	}
+	rel, err := filepath.Rel(root, file)
+	if err != nil {
+		return false
+	}
+	relDir := filepath.Dir(rel)
+	return stack == rel || stack == relDir || stack == filepath.Base(filepath.Dir(file))
+}
+
+// resolveTargets turns "stack" and "stack:service" arguments into targets,
+// in discovery order. Services of the same stack are merged, and a bare stack
+// argument selects the whole file. With no arguments every file is targeted.
+func resolveTargets(root string, files []string, args []string) ([]Target, error) {
+	if len(args) == 0 {
+		targets := make([]Target, len(files))
+		for i, f := range files {
+			targets[i] = Target{File: f}
+		}
+		return targets, nil
+	}
+
+	whole := make(map[string]bool)
+	services := make(map[string][]string)
+	for _, arg := range args {
+		stack, service := splitTarget(arg)
+		var matched []string
+		for _, f := range files {
+			if matchesStack(root, f, stack) {
+				matched = append(matched, f)
+			}
+		}
+		switch len(matched) {
+		case 0:
+			return nil, fmt.Errorf("no compose file matches %q", stack)
+		case 1:
+		default:
+			return nil, fmt.Errorf("%q is ambiguous, it matches: %s", stack, strings.Join(matched, ", "))
+		}
+		if service == "" {
+			whole[matched[0]] = true
+		} else {
+			services[matched[0]] = append(services[matched[0]], service)
+		}
+	}
+
+	var targets []Target
+	for _, f := range files {
+		switch {
+		case whole[f]:
+			targets = append(targets, Target{File: f})
+		case len(services[f]) > 0:
+			targets = append(targets, Target{File: f, Services: services[f]})
+		}
+	}
+	return targets, nil
+}
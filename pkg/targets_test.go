@@ -0,0 +1,92 @@
+package ahab
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_resolveTargets(t *testing.T) {
+	root := "/docker"
+	files := []string{
+		"/docker/apps/grafana/compose.yaml",
+		"/docker/apps/web/compose.yaml",
+		"/docker/old/web/compose.yaml",
+		"/docker/traefik/docker-compose.yml",
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []Target
+		wantErr bool
+	}{
+		{
+			name: "no args targets everything",
+			args: nil,
+			want: []Target{
+				{File: files[0]}, {File: files[1]}, {File: files[2]}, {File: files[3]},
+			},
+		},
+		{
+			name: "directory name and service merge in discovery order",
+			args: []string{"traefik", "grafana:db", "grafana:app"},
+			want: []Target{
+				{File: files[0], Services: []string{"db", "app"}},
+				{File: files[3]},
+			},
+		},
+		{
+			name: "whole stack wins over its services",
+			args: []string{"traefik:proxy", "traefik"},
+			want: []Target{{File: files[3]}},
+		},
+		{
+			name: "relative directory disambiguates",
+			args: []string{"apps/web:nginx"},
+			want: []Target{{File: files[1], Services: []string{"nginx"}}},
+		},
+		{
+			name: "relative file path",
+			args: []string{"traefik/docker-compose.yml"},
+			want: []Target{{File: files[3]}},
+		},
+		{
+			name:    "ambiguous directory name",
+			args:    []string{"web"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown stack",
+			args:    []string{"nope"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveTargets(root, files, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveTargets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parsePs(t *testing.T) {
+	lines := []byte(`{"Service":"web","State":"running"}
+{"Service":"db","State":"exited","Health":""}`)
+	array := []byte(`[{"Service":"web","State":"running"},{"Service":"db","State":"running"}]`)
+
+	if got := parsePs(lines); len(got) != 2 || got[1].Service != "db" || got[1].State != "exited" {
+		t.Errorf("parsePs(lines) = %+v", got)
+	}
+	if got := parsePs(array); len(got) != 2 || got[0].Service != "web" {
+		t.Errorf("parsePs(array) = %+v", got)
+	}
+	if got := parsePs([]byte("[]")); len(got) != 0 {
+		t.Errorf("parsePs(empty) = %+v", got)
+	}
+}
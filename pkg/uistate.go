@@ -0,0 +1,61 @@
+package ahab
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// UIState is what the TUI remembers between sessions.
+type UIState struct {
+	// Sort is the stack list's sort order.
+	Sort string `json:"sort,omitempty"`
+}
+
+// UIStatePath returns the file the TUI's state is kept in.
+func UIStatePath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ui.json"), nil
+}
+
+// LoadUIState reads the TUI's state. A missing file yields the zero state.
+func LoadUIState(path string) (UIState, error) {
+	var s UIState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, err
+	}
+	err = json.Unmarshal(data, &s)
+	return s, err
+}
+
+// SaveUIState replaces the TUI's state file with s.
+func SaveUIState(path string, s UIState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".ui-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,29 @@
+package ahab
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUIState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "ui.json")
+	if s, err := LoadUIState(path); err != nil || s != (UIState{}) {
+		t.Fatalf("LoadUIState() on missing file = %+v, %v", s, err)
+	}
+
+	want := UIState{Sort: "status"}
+	if err := SaveUIState(path, want); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LoadUIState(path); err != nil || got != want {
+		t.Errorf("LoadUIState() = %+v, %v, want %+v", got, err, want)
+	}
+
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadUIState(path); err == nil {
+		t.Error("LoadUIState() on a bad file: want an error")
+	}
+}
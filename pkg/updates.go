@@ -0,0 +1,98 @@
+package ahab
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// CheckUpdates returns the images of a compose file whose tag points to a
+// newer image in the registry than the one pulled. Images that were never
+// pulled or were built locally are skipped.
+func CheckUpdates(ctx context.Context, file string) ([]string, error) {
+	out, err := exec.CommandContext(ctx, "docker", "compose", "-f", file, "config", "--images").Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker compose config: %w", err)
+	}
+	var updates []string
+	var errs []error
+	seen := make(map[string]bool)
+	for _, image := range strings.Fields(string(out)) {
+		if seen[image] {
+			continue
+		}
+		seen[image] = true
+		local, err := localDigests(ctx, image)
+		if err != nil || len(local) == 0 {
+			continue
+		}
+		remote, err := remoteDigest(ctx, image)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", image, err))
+			continue
+		}
+		if !hasDigest(local, remote) {
+			updates = append(updates, image)
+		}
+	}
+	return updates, errors.Join(errs...)
+}
+
+// localDigests returns the registry digests a local image was pulled by.
+func localDigests(ctx context.Context, image string) ([]string, error) {
+	out, err := exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{json .RepoDigests}}", image).Output()
+	if err != nil {
+		return nil, err
+	}
+	var digests []string
+	err = json.Unmarshal(bytes.TrimSpace(out), &digests)
+	return digests, err
+}
+
+// remoteDigest returns the digest the registry serves for an image's tag:
+// the hash of its raw manifest, or manifest list for multi-platform images.
+func remoteDigest(ctx context.Context, image string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", "buildx", "imagetools", "inspect", "--raw", image)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", err
+	}
+	sum := sha256.Sum256(out)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// hasDigest reports whether one of an image's repo digests, such as
+// "nginx@sha256:...", is the given digest.
+func hasDigest(repoDigests []string, digest string) bool {
+	for _, d := range repoDigests {
+		if _, hash, ok := strings.Cut(d, "@"); ok && hash == digest {
+			return true
+		}
+	}
+	return false
+}
+
+// DockerHost returns the daemon the docker CLI talks to: $DOCKER_HOST, or
+// the endpoint of the current context.
+func DockerHost(ctx context.Context) (string, error) {
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		return host, nil
+	}
+	out, err := exec.CommandContext(ctx, "docker", "context", "inspect", "--format", "{{.Endpoints.docker.Host}}").Output()
+	if err != nil {
+		return "", fmt.Errorf("docker context inspect: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
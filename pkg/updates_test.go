@@ -0,0 +1,35 @@
+package ahab
+
+import "testing"
+
+func Test_hasDigest(t *testing.T) {
+	digests := []string{
+		"nginx@sha256:aaa",
+		"registry.example.com/nginx@sha256:bbb",
+	}
+	tests := []struct {
+		digest string
+		want   bool
+	}{
+		{"sha256:aaa", true},
+		{"sha256:bbb", true},
+		{"sha256:ccc", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := hasDigest(digests, tt.digest); got != tt.want {
+			t.Errorf("hasDigest(%q) = %v, want %v", tt.digest, got, tt.want)
+		}
+	}
+}
+
+func Test_summarizeState(t *testing.T) {
+	got := summarizeState([]containerPs{
+		{Service: "web", State: "running", Health: "healthy"},
+		{Service: "db", State: "running", Health: "unhealthy"},
+		{Service: "worker", State: "exited"},
+	})
+	if want := (ComposeState{Status: "partial", Unhealthy: 1}); got != want {
+		t.Errorf("summarizeState() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,130 @@
+package ahab
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// watchPollInterval is how often the polling watcher rescans a tree.
+const watchPollInterval = 2 * time.Second
+
+// WatchDir watches a tree of compose files for stacks being added, removed
+// or renamed, and for changes to .ahabignore. It sends on the returned
+// channel once changes have settled for the debounce delay, and closes it
+// when ctx is done. It uses inotify where available and otherwise polls.
+func WatchDir(ctx context.Context, dir string, debounce time.Duration) (<-chan struct{}, error) {
+	raw := make(chan struct{}, 1)
+	changed := func() {
+		select {
+		case raw <- struct{}{}:
+		default:
+		}
+	}
+	if err := watchInotify(ctx, dir, changed); err != nil {
+		snap, err := snapshotDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		go pollDir(ctx, dir, snap, watchPollInterval, changed)
+	}
+
+	out := make(chan struct{}, 1)
+	go func() {
+		defer close(out)
+		var timer <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-raw:
+				timer = time.After(debounce)
+			case <-timer:
+				timer = nil
+				select {
+				case out <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// watched reports whether a change to a file with this name can change the
+// discovered stacks.
+func watched(name string) bool {
+	return name == ".ahabignore" || !strings.HasPrefix(name, ".") && yamlRegex.MatchString(name)
+}
+
+type fileStamp struct {
+	mod  time.Time
+	size int64
+}
+
+// snapshotDir records the directories and watched files under dir.
+func snapshotDir(dir string) (map[string]fileStamp, error) {
+	snap := make(map[string]fileStamp)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path != dir && os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			snap[path] = fileStamp{}
+			return nil
+		}
+		if !watched(d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		snap[path] = fileStamp{mod: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return snap, err
+}
+
+// pollDir rescans dir every interval until ctx is done, calling changed
+// when its snapshot differs from the last one.
+func pollDir(ctx context.Context, dir string, last map[string]fileStamp, interval time.Duration, changed func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		snap, err := snapshotDir(dir)
+		if err != nil {
+			continue
+		}
+		if !sameSnapshot(last, snap) {
+			changed()
+		}
+		last = snap
+	}
+}
+
+func sameSnapshot(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, s := range a {
+		if t, ok := b[path]; !ok || !s.mod.Equal(t.mod) || s.size != t.size {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+package ahab
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_FROM |
+	syscall.IN_MOVED_TO | syscall.IN_CLOSE_WRITE | syscall.IN_DELETE_SELF
+
+// inotifyWatcher watches every directory of a tree with inotify.
+type inotifyWatcher struct {
+	fd   int
+	file *os.File
+	dirs map[int32]string
+}
+
+// watchInotify watches dir with inotify until ctx is done, calling changed
+// for each relevant event. It fails if inotify is unavailable or runs out of
+// watches, so the caller can poll instead.
+func watchInotify(ctx context.Context, dir string, changed func()) error {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return err
+	}
+	// A non-blocking descriptor is read through the runtime poller, so
+	// closing the file unblocks a pending read.
+	w := &inotifyWatcher{fd: fd, file: os.NewFile(uintptr(fd), "inotify"), dirs: make(map[int32]string)}
+	if err := w.addTree(dir); err != nil {
+		w.file.Close()
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		w.file.Close()
+	}()
+	go w.run(changed)
+	return nil
+}
+
+// addTree watches dir and the directories beneath it that discovery visits.
+func (w *inotifyWatcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path != dir && os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && skipDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		wd, err := syscall.InotifyAddWatch(w.fd, path, inotifyMask)
+		if err != nil {
+			if path != dir && err == syscall.ENOENT {
+				return nil
+			}
+			return err
+		}
+		w.dirs[int32(wd)] = path
+		return nil
+	})
+}
+
+func (w *inotifyWatcher) run(changed func()) {
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			nameStart := off + syscall.SizeofInotifyEvent
+			name := string(trimNul(buf[nameStart : nameStart+int(ev.Len)]))
+			off = nameStart + int(ev.Len)
+
+			switch {
+			case ev.Mask&syscall.IN_Q_OVERFLOW != 0:
+				changed()
+			case ev.Mask&syscall.IN_IGNORED != 0:
+				delete(w.dirs, ev.Wd)
+			case ev.Mask&syscall.IN_ISDIR != 0:
+				if skipDir(name) {
+					continue
+				}
+				if ev.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+					// Watch the new directory before rescanning, so files
+					// created in it from now on are seen. Running out of
+					// watches only means missing changes beneath it.
+					w.addTree(filepath.Join(w.dirs[ev.Wd], name))
+				}
+				changed()
+			case ev.Mask&syscall.IN_DELETE_SELF != 0 || watched(name):
+				changed()
+			}
+		}
+	}
+}
+
+func trimNul(b []byte) []byte {
+	for i, c := range b {
+		if c == 0 {
+			return b[:i]
+		}
+	}
+	return b
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package ahab
+
+import (
+	"context"
+	"errors"
+)
+
+// watchInotify is only available on Linux; elsewhere WatchDir polls.
+func watchInotify(ctx context.Context, dir string, changed func()) error {
+	return errors.New("inotify is not supported on this platform")
+}
@@ -0,0 +1,73 @@
+package ahab
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"media/compose.yaml", "media/.env", "node_modules/x.yaml", ".git/config.yaml", "notes.txt"} {
+		path := filepath.Join(dir, f)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		os.WriteFile(path, []byte("x"), 0o644)
+	}
+	snap, err := snapshotDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for path := range snap {
+		rel, _ := filepath.Rel(dir, path)
+		got = append(got, rel)
+	}
+	want := map[string]bool{".": true, "media": true, "media/compose.yaml": true}
+	if len(got) != len(want) {
+		t.Fatalf("snapshotDir() = %v, want %v", got, want)
+	}
+	for _, p := range got {
+		if !want[p] {
+			t.Errorf("snapshotDir() has unexpected %s", p)
+		}
+	}
+
+	before := snap
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("changed"), 0o644)
+	snap, _ = snapshotDir(dir)
+	if !sameSnapshot(before, snap) {
+		t.Errorf("an unwatched file changed the snapshot")
+	}
+	os.WriteFile(filepath.Join(dir, ".ahabignore"), []byte("media/\n"), 0o644)
+	snap, _ = snapshotDir(dir)
+	if sameSnapshot(before, snap) {
+		t.Errorf(".ahabignore did not change the snapshot")
+	}
+}
+
+func TestWatchDir(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	changes, err := WatchDir(ctx, dir, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A burst of changes, including in a new directory, settles into one
+	// notification.
+	stack := filepath.Join(dir, "media")
+	os.Mkdir(stack, 0o755)
+	os.WriteFile(filepath.Join(stack, "compose.yaml"), []byte("services: {}\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, ".ahabignore"), []byte("media/\n"), 0o644)
+	select {
+	case <-changes:
+	case <-time.After(2 * watchPollInterval):
+		t.Fatal("no change notification")
+	}
+
+	cancel()
+	for range changes {
+	}
+}